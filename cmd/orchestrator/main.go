@@ -4,58 +4,132 @@ import (
 	"context"
 	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/api"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/definitions"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/executors"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/grpcapi"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/handlers"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/metrics"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/tracing"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/mocks"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func main() {
 	injectFailure := flag.Float64("inject-failure", 0.0, "Probability of injected failure (0.0 to 1.0)")
+	outboxInterval := flag.Duration("outbox-interval", 200*time.Millisecond, "How often the outbox relay polls for tasks to publish when the outbox is empty")
+	workflowFiles := flag.String("workflow", "", "Comma-separated YAML or JSON workflow definitions; the first is started for new orders, the rest can run as child workflows (defaults to the built-in order fulfillment flow)")
+	grpcAddr := flag.String("grpc-addr", ":9090", "Address the gRPC workflow service listens on")
 	flag.Parse()
 
+	catalog := usecases.NewCatalog()
+	defs := []*domain.WorkflowDefinition{catalog.Default()}
+	if *workflowFiles != "" {
+		var err error
+		defs, err = definitions.LoadFiles(strings.Split(*workflowFiles, ","))
+		if err != nil {
+			log.Fatalf("Failed to load workflow definitions: %v", err)
+		}
+		if err := catalog.Use(defs); err != nil {
+			log.Fatalf("Failed to use workflow definitions: %v", err)
+		}
+	}
+
 	// initialize tracing
 	cleanup := tracing.InitTracing()
 	defer cleanup()
 
-	// initialize metrics
+	// build the orchestrator on one connection pool and queue client
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Sync()
+	cfg := config.Load()
+	db := conn.ConnectPostgres(cfg.DSN())
+	defer db.Close()
+	client := queue.NewQueueClient()
+	defer client.Close()
+
+	deps := usecases.PostgresDependencies(cfg, db, queue.NewPublisher(client))
+	deps.Definitions = catalog
+	deps.Logger = logger
+	orchestrator := usecases.NewOrchestrator(deps)
+
+	// initialize metrics and the workflow API
 	metrics.InitMetrics()
 	go func() {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", metrics.MetricsHandler())
+		api.Register(mux, orchestrator)
 		if err := http.ListenAndServe(":2112", mux); err != nil {
-			log.Fatalf("Failed to start metrics server: %w", err)
+			log.Fatalf("Failed to start HTTP server: %v", err)
+		}
+	}()
+
+	// start the gRPC workflow service
+	grpcServer := grpc.NewServer()
+	grpcapi.Register(grpcServer, orchestrator)
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", *grpcAddr, err)
+	}
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
 		}
 	}()
 
+	// register step executors and compensators
+	registry := executors.NewRegistry()
+	mocks.Register(registry, deps.Agents)
+	for _, def := range defs {
+		if err := registry.Check(def); err != nil {
+			log.Fatalf("Workflow definition %s cannot run: %v", def.Name, err)
+		}
+	}
+	h := handlers.New(orchestrator, registry, deps.Steps, deps.Compensations)
+
 	// set chaos
-	handlers.SetFailureProbability(*injectFailure)
+	h.SetFailureProbability(*injectFailure)
 
 	// start asynq server
 	server := queue.NewQueueServer()
 	mux := queue.NewServeMux()
-	mux.HandleFunc("step", handlers.HandleStep)
-	mux.HandleFunc("compensation", handlers.HandleCompensation)
+	h.Register(mux)
 
 	go func() {
 		if err := server.Run(mux); err != nil {
-			log.Fatalf("Asynq sever error ", err)
+			log.Fatalf("Asynq server error: %v", err)
 		}
 	}()
 
+	// publish the tasks usecases record in the outbox
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	go orchestrator.RunOutboxRelay(relayCtx, *outboxInterval)
+
 	log.Println("Orchestrator running. Press Ctrl+C to stop.")
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	<-sigs
 
-	_, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	stopRelay()
+	grpcServer.Stop()
 	server.Shutdown()
 	log.Println("Orchestrator stopped")
 }
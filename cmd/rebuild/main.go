@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
+)
+
+// rebuild rewrites the workflows table and the snapshots of event-sourced
+// workflows from their full event streams, for instance after a schema change.
+func main() {
+	orderID := flag.String("order", "", "Only rebuild the workflow of this order")
+	flag.Parse()
+
+	cfg := config.Load()
+	db := conn.ConnectPostgres(cfg.DSN())
+	defer db.Close()
+
+	ctx := context.Background()
+	orderIDs := []string{*orderID}
+	if *orderID == "" {
+		var err error
+		if orderIDs, err = repositories.NewWorkflowEventRepo(db).GetOrderIDs(ctx); err != nil {
+			log.Fatalf("Failed to list event streams: %v", err)
+		}
+	}
+
+	workflowRepo := repositories.NewEventSourcedWorkflowRepo(db)
+	failed := 0
+	for _, id := range orderIDs {
+		state, err := workflowRepo.RebuildProjection(ctx, id)
+		if err != nil {
+			log.Printf("Failed to rebuild workflow %s: %v", id, err)
+			failed++
+			continue
+		}
+		log.Printf("Rebuilt workflow %s at version %d (%s)", id, state.Version, state.Status)
+	}
+	log.Printf("Rebuilt %d of %d workflows", len(orderIDs)-failed, len(orderIDs))
+	if failed > 0 {
+		log.Fatalf("%d workflows could not be rebuilt", failed)
+	}
+}
@@ -4,21 +4,51 @@ import (
 	"context"
 	"flag"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/definitions"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
 )
 
 func main() {
 	timeout := flag.Duration("timeout", 5*time.Minute, "Consider workflows stalled if not updated for this duration")
+	workflowFiles := flag.String("workflow", "", "Comma-separated YAML or JSON workflow definitions the orchestrator is running with")
+	retryCompensation := flag.String("retry-compensation", "", "Order ID of a workflow in compensation_failed whose rollback should be retried")
 	flag.Parse()
 
+	catalog := usecases.NewCatalog()
+	if *workflowFiles != "" {
+		defs, err := definitions.LoadFiles(strings.Split(*workflowFiles, ","))
+		if err != nil {
+			log.Fatalf("Failed to load workflow definitions: %v", err)
+		}
+		if err := catalog.Use(defs); err != nil {
+			log.Fatalf("Failed to use workflow definitions: %v", err)
+		}
+	}
+
 	cfg := config.Load()
 	db := conn.ConnectPostgres(cfg.DSN())
 	defer db.Close()
+	client := queue.NewQueueClient()
+	defer client.Close()
+	deps := usecases.PostgresDependencies(cfg, db, queue.NewPublisher(client))
+	deps.Definitions = catalog
+	orchestrator := usecases.NewOrchestrator(deps)
+
+	if *retryCompensation != "" {
+		if err := orchestrator.RetryCompensation(context.Background(), *retryCompensation); err != nil {
+			log.Fatalf("Failed to retry rollback of %s: %v", *retryCompensation, err)
+		}
+		log.Printf("Retrying rollback of workflow %s", *retryCompensation)
+		return
+	}
 
 	workflowRepo := repositories.NewWorkflowRepo(db)
 	stalled, err := workflowRepo.GetStalledWorkflows(context.Background(), *timeout)
@@ -28,15 +58,38 @@ func main() {
 
 	log.Printf("Found %d stalled workflows\n", len(stalled))
 
-	client := queue.NewQueueClient()
-	defer client.Close()
-
 	for _, state := range stalled {
-		payload := queue.StepPayload{OrderID: state.OrderID, Step: state.CurrentStep}
-		if err := queue.EnqueueStep(context.Background(), client, "step", payload); err != nil {
-			log.Printf("Failed to reenqueue %s: %v", state.OrderID, err)
-		} else {
-			log.Printf("Reenqueued stalled workflow: %s (step: %s)", state.OrderID, state.CurrentStep)
+		if state.Status == domain.StatusCompensating {
+			if err := orchestrator.ResumeCompensation(context.Background(), state.OrderID); err != nil {
+				log.Printf("Failed to resume rollback of %s: %v", state.OrderID, err)
+			} else {
+				log.Printf("Resumed rollback of workflow %s (pending: %v)", state.OrderID, state.PendingCompensations)
+			}
+			continue
+		}
+		def, err := catalog.For(state)
+		if err != nil {
+			log.Printf("Failed to recover %s: %v", state.OrderID, err)
+			continue
+		}
+		// a parallel group has one active step per unfinished branch
+		for _, step := range state.ActiveSteps {
+			stepDef, _ := def.Step(step)
+			payload := queue.StepPayload{OrderID: state.OrderID, Step: step, Retry: stepDef.Retry.WithDefaults()}
+			if wakeAt, ok := state.Timers[step]; ok {
+				// rescheduling a pending timer is a no-op, so it never fires twice
+				if err := queue.EnqueueTimer(context.Background(), client, payload, wakeAt); err != nil {
+					log.Printf("Failed to reschedule timer of %s: %v", state.OrderID, err)
+				} else {
+					log.Printf("Timer of workflow %s (step: %s) is scheduled for %s", state.OrderID, step, wakeAt.Format(time.RFC3339))
+				}
+				continue
+			}
+			if err := queue.EnqueueStep(context.Background(), client, "step", payload, queue.RetryOptions(payload.Retry)...); err != nil {
+				log.Printf("Failed to reenqueue %s: %v", state.OrderID, err)
+			} else {
+				log.Printf("Reenqueued stalled workflow: %s (step: %s)", state.OrderID, step)
+			}
 		}
 	}
 }
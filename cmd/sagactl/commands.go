@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+)
+
+const defaultPageSize = 50
+
+func parseGet(args []string) (action, error) {
+	fs, format := newFlagSet("get")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		details, err := o.GetWorkflow(ctx, positional[0])
+		if err != nil {
+			return err
+		}
+		return printWorkflow(*format, details)
+	}, nil
+}
+
+func parseList(args []string) (action, error) {
+	fs, format := newFlagSet("list")
+	statuses := fs.String("status", "", "Comma-separated statuses to list")
+	createdAfter := fs.String("created-after", "", "Only workflows created at or after this RFC 3339 time")
+	createdBefore := fs.String("created-before", "", "Only workflows created before this RFC 3339 time")
+	limit := fs.Int("limit", defaultPageSize, "Maximum number of workflows to list")
+	cursor := fs.String("cursor", "", "Cursor of the page to list, from a previous list")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	filter := domain.WorkflowFilter{Limit: *limit}
+	if *limit < 1 {
+		return nil, fmt.Errorf("limit must be at least 1")
+	}
+	if *statuses != "" {
+		for _, status := range strings.Split(*statuses, ",") {
+			filter.Statuses = append(filter.Statuses, domain.WorkflowStatus(status))
+		}
+	}
+	for value, t := range map[string]*time.Time{*createdAfter: &filter.CreatedAfter, *createdBefore: &filter.CreatedBefore} {
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: %w", value, err)
+		}
+		*t = parsed
+	}
+	if *cursor != "" {
+		after, err := domain.DecodeWorkflowCursor(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		states, next, err := o.ListWorkflows(ctx, filter)
+		if err != nil {
+			return err
+		}
+		return printList(*format, states, next)
+	}, nil
+}
+
+func parseHistory(args []string) (action, error) {
+	fs, format := newFlagSet("history")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		transitions, err := o.GetHistory(ctx, positional[0])
+		if err != nil {
+			return err
+		}
+		return printHistory(*format, transitions)
+	}, nil
+}
+
+func parseRetry(args []string) (action, error) {
+	fs, format := newFlagSet("retry")
+	from := fs.String("from-step", "", "Step to run the workflow again from (defaults to the steps it is stuck on, or the first step of a rolled back workflow)")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	orderID := positional[0]
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		if err := o.RetryWorkflow(ctx, orderID, domain.Step(*from)); err != nil {
+			return err
+		}
+		return report(ctx, o, *format, orderID, "Retrying workflow %s", orderID)
+	}, nil
+}
+
+func parseCancel(args []string) (action, error) {
+	fs, format := newFlagSet("cancel")
+	reason := fs.String("reason", "", "Why the workflow is cancelled, recorded in its history")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	orderID := positional[0]
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		if err := o.CancelWorkflow(ctx, orderID, *reason); err != nil {
+			return err
+		}
+		return report(ctx, o, *format, orderID, "Cancelled workflow %s", orderID)
+	}, nil
+}
+
+func parseCompensate(args []string) (action, error) {
+	fs, format := newFlagSet("compensate")
+	reason := fs.String("reason", "", "Why the workflow is rolled back, recorded in its history")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	orderID := positional[0]
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		if err := o.CompensateWorkflow(ctx, orderID, *reason); err != nil {
+			return err
+		}
+		return report(ctx, o, *format, orderID, "Rolling back workflow %s", orderID)
+	}, nil
+}
+
+func parseSkipStep(args []string) (action, error) {
+	fs, format := newFlagSet("skip-step")
+	stepOutput := fs.String("step-output", "", "JSON object recorded as the output of the step")
+	positional, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return nil, err
+	}
+	var output map[string]any
+	if *stepOutput != "" {
+		if err := json.Unmarshal([]byte(*stepOutput), &output); err != nil {
+			return nil, fmt.Errorf("invalid step output: %w", err)
+		}
+	}
+	orderID, step := positional[0], domain.Step(positional[1])
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		if err := o.SkipStep(ctx, orderID, step, output); err != nil {
+			return err
+		}
+		return report(ctx, o, *format, orderID, "Skipped step %s of workflow %s", step, orderID)
+	}, nil
+}
+
+func parsePause(args []string) (action, error) {
+	fs, format := newFlagSet("pause")
+	step := fs.String("step", "", "Pause this step in every workflow")
+	all := fs.Bool("all", false, "Pause every step in every workflow")
+	reason := fs.String("reason", "", "Why the workflows are paused, recorded in their history")
+	orderID, err := parseTarget(fs, args, step, all)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		switch {
+		case orderID != "":
+			if err := o.PauseWorkflow(ctx, orderID, *reason); err != nil {
+				return err
+			}
+			return report(ctx, o, *format, orderID, "Paused workflow %s", orderID)
+		case *all:
+			if err := o.PauseAll(ctx, *reason); err != nil {
+				return err
+			}
+			return reportPauses(ctx, o, *format, "Paused every step")
+		default:
+			if err := o.PauseStep(ctx, domain.Step(*step), *reason); err != nil {
+				return err
+			}
+			return reportPauses(ctx, o, *format, "Paused step %s", *step)
+		}
+	}, nil
+}
+
+func parseResume(args []string) (action, error) {
+	fs, format := newFlagSet("resume")
+	step := fs.String("step", "", "Lift the pause of this step")
+	all := fs.Bool("all", false, "Lift every pause and resume the workflows they held")
+	orderID, err := parseTarget(fs, args, step, all)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		switch {
+		case orderID != "":
+			if err := o.ResumeWorkflow(ctx, orderID); err != nil {
+				return err
+			}
+			return report(ctx, o, *format, orderID, "Resumed workflow %s", orderID)
+		case *all:
+			resumed, err := o.ResumeAll(ctx)
+			if err != nil {
+				return err
+			}
+			return reportPauses(ctx, o, *format, "Lifted every pause, resumed %d workflow(s)", resumed)
+		default:
+			resumed, err := o.ResumeStep(ctx, domain.Step(*step))
+			if err != nil {
+				return err
+			}
+			return reportPauses(ctx, o, *format, "Lifted the pause of step %s, resumed %d workflow(s)", *step, resumed)
+		}
+	}, nil
+}
+
+func parsePauses(args []string) (action, error) {
+	fs, format := newFlagSet("pauses")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, o *usecases.Orchestrator) error {
+		pauses, err := o.Pauses(ctx)
+		if err != nil {
+			return err
+		}
+		return printPauses(*format, pauses)
+	}, nil
+}
+
+// parseTarget parses the arguments of pause and resume, which take an order,
+// --step or --all, and returns the order if that is the one given.
+func parseTarget(fs *flag.FlagSet, args []string, step *string, all *bool) (string, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(positional) > 1 {
+		return "", fmt.Errorf("expected at most 1 argument, got %d", len(positional))
+	}
+	targets := 0
+	for _, given := range []bool{len(positional) == 1, *step != "", *all} {
+		if given {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return "", fmt.Errorf("expected one of <order>, --step or --all")
+	}
+	if len(positional) == 1 {
+		return positional[0], nil
+	}
+	return "", nil
+}
+
+// newFlagSet returns the flags of the command name, with the output format
+// every command takes.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("sagactl "+name, flag.ContinueOnError)
+	format := fs.String("o", formatTable, "Output format: table or json")
+	return fs, format
+}
+
+// parseArgs parses args with fs, accepting flags after the positional
+// arguments too, and returns the n positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != n {
+		return nil, fmt.Errorf("expected %d argument(s), got %d", n, len(positional))
+	}
+	return positional, nil
+}
+
+// parseFlags parses args with fs like parseArgs, and returns the positional
+// arguments, however many.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if format := fs.Lookup("o").Value.String(); format != formatTable && format != formatJSON {
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+	return positional, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/definitions"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
+)
+
+// command is a sagactl subcommand. parse gets the arguments after its name
+// and returns what to run, so bad arguments fail before connecting.
+type command struct {
+	name    string
+	args    string
+	summary string
+	parse   func(args []string) (action, error)
+}
+
+type action func(ctx context.Context, o *usecases.Orchestrator) error
+
+var commands = []command{
+	{"get", "<order>", "Show a workflow with its order data, step outputs and agents", parseGet},
+	{"list", "[--status s1,s2] [--created-after t] [--created-before t] [--limit n] [--cursor c]", "List workflows, newest first", parseList},
+	{"history", "<order>", "Show the transitions of a workflow", parseHistory},
+	{"retry", "<order> [--from-step step]", "Run a workflow again from a step, or retry its parked rollback", parseRetry},
+	{"cancel", "<order> [--reason text]", "Stop a running workflow, compensate its completed steps and mark it cancelled", parseCancel},
+	{"compensate", "<order> [--reason text]", "Roll back a running or completed workflow", parseCompensate},
+	{"skip-step", "<order> <step> [--step-output json]", "Mark an active step succeeded without running it", parseSkipStep},
+	{"pause", "<order> | --step step | --all [--reason text]", "Pause a running workflow, or hold a step, or every step, in all workflows", parsePause},
+	{"resume", "<order> | --step step | --all", "Resume a paused workflow, or lift a pause and resume the workflows it held", parseResume},
+	{"pauses", "", "List the steps paused in all workflows", parsePauses},
+}
+
+// sagactl inspects and repairs workflows, on the same database and queue as
+// the orchestrator. Tasks are enqueued through the outbox, so changes take
+// effect once a running orchestrator relays them.
+func main() {
+	workflowFiles := flag.String("workflow", "", "Comma-separated YAML or JSON workflow definitions the orchestrator is running with")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+	if name == "help" {
+		usage()
+		return
+	}
+	i := commandIndex(name)
+	if i < 0 {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	run, err := commands[i].parse(flag.Args()[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sagactl %s: %v\n", name, err)
+		os.Exit(2)
+	}
+
+	catalog := usecases.NewCatalog()
+	if *workflowFiles != "" {
+		defs, err := definitions.LoadFiles(strings.Split(*workflowFiles, ","))
+		if err != nil {
+			log.Fatalf("Failed to load workflow definitions: %v", err)
+		}
+		if err := catalog.Use(defs); err != nil {
+			log.Fatalf("Failed to use workflow definitions: %v", err)
+		}
+	}
+
+	cfg := config.Load()
+	db := conn.ConnectPostgres(cfg.DSN())
+	defer db.Close()
+	client := queue.NewQueueClient()
+	defer client.Close()
+	deps := usecases.PostgresDependencies(cfg, db, queue.NewPublisher(client))
+	deps.Definitions = catalog
+	orchestrator := usecases.NewOrchestrator(deps)
+
+	if err := run(context.Background(), orchestrator); err != nil {
+		fmt.Fprintf(os.Stderr, "sagactl %s: %v\n", name, err)
+		db.Close()
+		client.Close()
+		os.Exit(1)
+	}
+}
+
+func commandIndex(name string) int {
+	for i, c := range commands {
+		if c.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: sagactl [--workflow files] <command> [flags] [-o table|json]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-11s %s\n", c.name, c.summary)
+		fmt.Fprintf(out, "  %-11s   sagactl %s %s\n", "", c.name, c.args)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
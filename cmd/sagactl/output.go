@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+type workflowView struct {
+	OrderID              string                         `json:"order_id"`
+	Workflow             string                         `json:"workflow"`
+	WorkflowVersion      int                            `json:"workflow_version"`
+	ParentOrderID        string                         `json:"parent_order_id,omitempty"`
+	ParentStep           domain.Step                    `json:"parent_step,omitempty"`
+	Status               domain.WorkflowStatus          `json:"status"`
+	CurrentStep          domain.Step                    `json:"current_step"`
+	ActiveSteps          []domain.Step                  `json:"active_steps"`
+	CompletedSteps       []domain.Step                  `json:"completed_steps"`
+	PendingCompensations []domain.Step                  `json:"pending_compensations,omitempty"`
+	StepOutputs          map[domain.Step]map[string]any `json:"step_outputs,omitempty"`
+	CompensationFailure  *domain.CompensationFailure    `json:"compensation_failure,omitempty"`
+	PausedBy             domain.PauseOrigin             `json:"paused_by,omitempty"`
+	OrderStatus          string                         `json:"order_status,omitempty"`
+	Data                 map[string]any                 `json:"data,omitempty"`
+	Agents               []string                       `json:"agents,omitempty"`
+	CreatedAt            time.Time                      `json:"created_at"`
+	UpdatedAt            time.Time                      `json:"updated_at"`
+}
+
+type transitionView struct {
+	ID         int64                 `json:"id"`
+	FromStep   domain.Step           `json:"from_step,omitempty"`
+	ToStep     domain.Step           `json:"to_step,omitempty"`
+	FromStatus domain.WorkflowStatus `json:"from_status,omitempty"`
+	ToStatus   domain.WorkflowStatus `json:"to_status"`
+	Cause      string                `json:"cause"`
+	Error      string                `json:"error,omitempty"`
+	Worker     string                `json:"worker"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+type pauseView struct {
+	Step      domain.Step `json:"step,omitempty"`
+	AllSteps  bool        `json:"all_steps,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// report prints message in table format, then the workflow of orderID as it
+// is after an operator changed it.
+func report(ctx context.Context, o *usecases.Orchestrator, format, orderID, message string, args ...any) error {
+	details, err := o.GetWorkflow(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if format == formatTable {
+		fmt.Printf(message+"\n\n", args...)
+	}
+	return printWorkflow(format, details)
+}
+
+// reportPauses prints message in table format, then the pauses in force.
+func reportPauses(ctx context.Context, o *usecases.Orchestrator, format, message string, args ...any) error {
+	pauses, err := o.Pauses(ctx)
+	if err != nil {
+		return err
+	}
+	if format == formatTable {
+		fmt.Printf(message+"\n\n", args...)
+	}
+	return printPauses(format, pauses)
+}
+
+func printWorkflow(format string, details *usecases.WorkflowDetails) error {
+	view := stateView(details.State)
+	view.StepOutputs = details.State.StepOutputs
+	view.Agents = details.Agents
+	if details.Order != nil {
+		view.OrderStatus = details.Order.Status
+		view.Data = details.Order.Data
+	}
+	if format == formatJSON {
+		return printJSON(view)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ORDER\t%s\n", view.OrderID)
+	fmt.Fprintf(w, "WORKFLOW\t%s v%d\n", view.Workflow, view.WorkflowVersion)
+	if view.ParentOrderID != "" {
+		fmt.Fprintf(w, "PARENT\t%s (step %s)\n", view.ParentOrderID, view.ParentStep)
+	}
+	fmt.Fprintf(w, "STATUS\t%s\n", view.Status)
+	fmt.Fprintf(w, "CURRENT STEP\t%s\n", orDash(string(view.CurrentStep)))
+	fmt.Fprintf(w, "ACTIVE STEPS\t%s\n", joinSteps(view.ActiveSteps))
+	fmt.Fprintf(w, "COMPLETED STEPS\t%s\n", joinSteps(view.CompletedSteps))
+	if len(view.PendingCompensations) > 0 {
+		fmt.Fprintf(w, "TO COMPENSATE\t%s\n", joinSteps(view.PendingCompensations))
+	}
+	if f := view.CompensationFailure; f != nil {
+		fmt.Fprintf(w, "ROLLBACK PARKED\t%s (%s) at %s: %s\n", f.Step, orDash(string(f.Compensation)), formatTime(f.FailedAt), f.Error)
+	}
+	if view.PausedBy != "" {
+		fmt.Fprintf(w, "PAUSED BY\t%s\n", view.PausedBy)
+	}
+	fmt.Fprintf(w, "ORDER STATUS\t%s\n", orDash(view.OrderStatus))
+	fmt.Fprintf(w, "AGENTS\t%s\n", orDash(strings.Join(view.Agents, ", ")))
+	fmt.Fprintf(w, "CREATED\t%s\n", formatTime(view.CreatedAt))
+	fmt.Fprintf(w, "UPDATED\t%s\n", formatTime(view.UpdatedAt))
+	fmt.Fprintf(w, "DATA\t%s\n", compactJSON(view.Data))
+	steps := make([]domain.Step, 0, len(view.StepOutputs))
+	for step := range view.StepOutputs {
+		steps = append(steps, step)
+	}
+	slices.Sort(steps)
+	for _, step := range steps {
+		fmt.Fprintf(w, "OUTPUT %s\t%s\n", step, compactJSON(view.StepOutputs[step]))
+	}
+	return w.Flush()
+}
+
+func printList(format string, states []*domain.WorkflowState, next *domain.WorkflowCursor) error {
+	var cursor string
+	if next != nil {
+		cursor = next.Encode()
+	}
+	if format == formatJSON {
+		views := make([]*workflowView, 0, len(states))
+		for _, state := range states {
+			views = append(views, stateView(state))
+		}
+		return printJSON(struct {
+			Workflows  []*workflowView `json:"workflows"`
+			NextCursor string          `json:"next_cursor,omitempty"`
+		}{views, cursor})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ORDER\tWORKFLOW\tSTATUS\tSTEP\tCREATED\tUPDATED")
+	for _, s := range states {
+		fmt.Fprintf(w, "%s\t%s v%d\t%s\t%s\t%s\t%s\n", s.OrderID, s.Workflow, s.WorkflowVersion, s.Status,
+			orDash(string(positionStep(s))), formatTime(s.CreatedAt), formatTime(s.UpdatedAt))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if cursor != "" {
+		fmt.Printf("\nMore workflows: --cursor %s\n", cursor)
+	}
+	return nil
+}
+
+func printHistory(format string, transitions []*domain.WorkflowTransition) error {
+	if format == formatJSON {
+		views := make([]*transitionView, 0, len(transitions))
+		for _, t := range transitions {
+			views = append(views, &transitionView{
+				ID:         t.ID,
+				FromStep:   t.FromStep,
+				ToStep:     t.ToStep,
+				FromStatus: t.FromStatus,
+				ToStatus:   t.ToStatus,
+				Cause:      t.Cause,
+				Error:      t.Error,
+				Worker:     t.Worker,
+				CreatedAt:  t.CreatedAt,
+			})
+		}
+		return printJSON(views)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tFROM\tTO\tCAUSE\tERROR\tWORKER")
+	for _, t := range transitions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, formatTime(t.CreatedAt),
+			position(t.FromStep, t.FromStatus), position(t.ToStep, t.ToStatus), t.Cause, orDash(t.Error), t.Worker)
+	}
+	return w.Flush()
+}
+
+func printPauses(format string, pauses []*domain.WorkflowPause) error {
+	if format == formatJSON {
+		views := make([]*pauseView, 0, len(pauses))
+		for _, p := range pauses {
+			views = append(views, &pauseView{Step: p.Step, AllSteps: p.Step == "", Reason: p.Reason, CreatedAt: p.CreatedAt})
+		}
+		return printJSON(views)
+	}
+
+	if len(pauses) == 0 {
+		fmt.Println("No steps are paused")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSINCE\tREASON")
+	for _, p := range pauses {
+		step := string(p.Step)
+		if step == "" {
+			step = "(every step)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", step, formatTime(p.CreatedAt), orDash(p.Reason))
+	}
+	return w.Flush()
+}
+
+func stateView(state *domain.WorkflowState) *workflowView {
+	return &workflowView{
+		OrderID:              state.OrderID,
+		Workflow:             state.Workflow,
+		WorkflowVersion:      state.WorkflowVersion,
+		ParentOrderID:        state.ParentOrderID,
+		ParentStep:           state.ParentStep,
+		Status:               state.Status,
+		CurrentStep:          state.CurrentStep,
+		ActiveSteps:          nonNil(state.ActiveSteps),
+		CompletedSteps:       nonNil(state.CompletedSteps),
+		PendingCompensations: state.PendingCompensations,
+		CompensationFailure:  state.CompensationFailure,
+		PausedBy:             state.PausedBy,
+		CreatedAt:            state.CreatedAt,
+		UpdatedAt:            state.UpdatedAt,
+	}
+}
+
+// positionStep is the step a workflow stands at: the next one to compensate
+// during a rollback, or its current step.
+func positionStep(state *domain.WorkflowState) domain.Step {
+	if state.Status.IsRollback() {
+		if len(state.PendingCompensations) == 0 {
+			return ""
+		}
+		return state.PendingCompensations[0]
+	}
+	return state.CurrentStep
+}
+
+func position(step domain.Step, status domain.WorkflowStatus) string {
+	if status == "" {
+		return "-"
+	}
+	if step == "" {
+		return string(status)
+	}
+	return fmt.Sprintf("%s (%s)", step, status)
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func compactJSON(v map[string]any) string {
+	if len(v) == 0 {
+		return "-"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func joinSteps(steps []domain.Step) string {
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = string(s)
+	}
+	return orDash(strings.Join(names, ", "))
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// nonNil returns steps, or an empty slice for nil so it is encoded as [].
+func nonNil(steps []domain.Step) []domain.Step {
+	if steps == nil {
+		return []domain.Step{}
+	}
+	return steps
+}
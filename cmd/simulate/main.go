@@ -4,22 +4,55 @@ import (
 	"context"
 	"flag"
 	"log"
+	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/definitions"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
 )
 
+var orderTypes = []string{"delivery", "walk_in"}
+
 func main() {
 	num := flag.Int("num", 10, "Number of orders to simulate")
 	delay := flag.Duration("delay", 500*time.Millisecond, "Delay between order creations")
+	workflowFiles := flag.String("workflow", "", "Comma-separated YAML or JSON workflow definitions; the first is started for new orders, the rest can run as child workflows (defaults to the built-in order fulfillment flow)")
 	flag.Parse()
 
+	catalog := usecases.NewCatalog()
+	if *workflowFiles != "" {
+		defs, err := definitions.LoadFiles(strings.Split(*workflowFiles, ","))
+		if err != nil {
+			log.Fatalf("Failed to load workflow definitions: %v", err)
+		}
+		if err := catalog.Use(defs); err != nil {
+			log.Fatalf("Failed to use workflow definitions: %v", err)
+		}
+	}
+
+	cfg := config.Load()
+	db := conn.ConnectPostgres(cfg.DSN())
+	defer db.Close()
+	client := queue.NewQueueClient()
+	defer client.Close()
+	deps := usecases.PostgresDependencies(cfg, db, queue.NewPublisher(client))
+	deps.Definitions = catalog
+	orchestrator := usecases.NewOrchestrator(deps)
+
 	log.Printf("Simulating %d orders...\n", *num)
 
 	for i := 0; i < *num; i++ {
 		orderID := uuid.New().String()
-		if err := usecases.StartWorkflow(context.Background(), orderID); err != nil {
+		data := map[string]any{
+			"type":  orderTypes[rand.Intn(len(orderTypes))],
+			"items": 1 + rand.Intn(20),
+		}
+		if err := orchestrator.StartWorkflow(context.Background(), orderID, data); err != nil {
 			log.Printf("Failed to start workflow for %s: %v", orderID, err)
 		} else {
 			log.Printf("Started workflow for order %s", orderID)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/definitions"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
+)
+
+// versions lists the workflow definition versions that still have running
+// workflows. Versions that are loaded but have none can be retired.
+func main() {
+	workflowFiles := flag.String("workflow", "", "Comma-separated YAML or JSON workflow definitions the orchestrator is running with")
+	flag.Parse()
+
+	catalog := usecases.NewCatalog()
+	if *workflowFiles != "" {
+		defs, err := definitions.LoadFiles(strings.Split(*workflowFiles, ","))
+		if err != nil {
+			log.Fatalf("Failed to load workflow definitions: %v", err)
+		}
+		if err := catalog.Use(defs); err != nil {
+			log.Fatalf("Failed to use workflow definitions: %v", err)
+		}
+	}
+
+	cfg := config.Load()
+	db := conn.ConnectPostgres(cfg.DSN())
+	defer db.Close()
+
+	workflowRepo := repositories.NewWorkflowRepo(db)
+	usages, err := workflowRepo.GetRunningVersions(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to get running workflow versions: %v", err)
+	}
+
+	running := make(map[string]int)
+	for _, u := range usages {
+		running[fmt.Sprintf("%s@%d", u.Workflow, u.Version)] = u.Running
+	}
+	loaded := make(map[string]bool)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKFLOW\tVERSION\tRUNNING\tSTATUS")
+	for _, def := range catalog.All() {
+		key := fmt.Sprintf("%s@%d", def.Name, def.Version)
+		loaded[key] = true
+		status := "in use"
+		if running[key] == 0 {
+			status = "can be retired"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", def.Name, def.Version, running[key], status)
+	}
+	for _, u := range usages {
+		if !loaded[fmt.Sprintf("%s@%d", u.Workflow, u.Version)] {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", u.Workflow, u.Version, u.Running, "NOT LOADED")
+		}
+	}
+	w.Flush()
+}
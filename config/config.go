@@ -9,24 +9,30 @@ import (
 )
 
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	RedisAddr  string
+	DBHost        string
+	DBPort        string
+	DBUser        string
+	DBPassword    string
+	DBName        string
+	RedisAddr     string
+	WorkflowStore string
 }
 
+// WorkflowStoreEvents stores workflows as event streams, with the workflows
+// table as their projection. Any other WORKFLOW_STORE uses the table alone.
+const WorkflowStoreEvents = "events"
+
 func Load() *Config {
 	_ = godotenv.Load() // ignore error if not found, fine for prod containers
 
 	cfg := &Config{
-		DBHost:     os.Getenv("DB_HOST"),
-		DBPort:     os.Getenv("DB_PORT"),
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBName:     os.Getenv("DB_NAME"),
-		RedisAddr:  os.Getenv("REDIS_ADDR"),
+		DBHost:        os.Getenv("DB_HOST"),
+		DBPort:        os.Getenv("DB_PORT"),
+		DBUser:        os.Getenv("DB_USER"),
+		DBPassword:    os.Getenv("DB_PASSWORD"),
+		DBName:        os.Getenv("DB_NAME"),
+		RedisAddr:     os.Getenv("REDIS_ADDR"),
+		WorkflowStore: os.Getenv("WORKFLOW_STORE"),
 	}
 
 	return cfg
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+)
+
+type server struct {
+	orchestrator *usecases.Orchestrator
+}
+
+// Register adds the workflow API routes of orchestrator to mux.
+func Register(mux *http.ServeMux, orchestrator *usecases.Orchestrator) {
+	s := &server{orchestrator: orchestrator}
+	mux.HandleFunc("POST /workflows", s.handleStart)
+	mux.HandleFunc("GET /workflows", s.handleList)
+	mux.HandleFunc("GET /workflows/{orderID...}", s.handleGet)
+	mux.HandleFunc("POST /workflows/{orderID}/cancel", s.handleCancel)
+	mux.HandleFunc("POST /workflows/{orderID}/signals/{name}", s.handleSignal)
+}
+
+// handleSignal delivers a signal to a waiting workflow. The optional JSON
+// object in the request body is passed on as the signal payload.
+func (s *server) handleSignal(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err := s.orchestrator.SignalWorkflow(r.Context(), r.PathValue("orderID"), r.PathValue("name"), payload)
+	switch {
+	case errors.Is(err, domain.ErrWorkflowNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrSignalNotExpected):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
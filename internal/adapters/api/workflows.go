@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type startRequest struct {
+	OrderID string         `json:"order_id"`
+	Data    map[string]any `json:"data"`
+}
+
+type workflowResponse struct {
+	OrderID              string                         `json:"order_id"`
+	Workflow             string                         `json:"workflow"`
+	WorkflowVersion      int                            `json:"workflow_version"`
+	ParentOrderID        string                         `json:"parent_order_id,omitempty"`
+	ParentStep           domain.Step                    `json:"parent_step,omitempty"`
+	Status               domain.WorkflowStatus          `json:"status"`
+	CurrentStep          domain.Step                    `json:"current_step"`
+	ActiveSteps          []domain.Step                  `json:"active_steps"`
+	CompletedSteps       []domain.Step                  `json:"completed_steps"`
+	PendingCompensations []domain.Step                  `json:"pending_compensations,omitempty"`
+	StepOutputs          map[domain.Step]map[string]any `json:"step_outputs,omitempty"`
+	CompensationFailure  *domain.CompensationFailure    `json:"compensation_failure,omitempty"`
+	PausedBy             domain.PauseOrigin             `json:"paused_by,omitempty"`
+	OrderStatus          string                         `json:"order_status,omitempty"`
+	Data                 map[string]any                 `json:"data,omitempty"`
+	Agents               []string                       `json:"agents,omitempty"`
+	CreatedAt            time.Time                      `json:"created_at"`
+	UpdatedAt            time.Time                      `json:"updated_at"`
+}
+
+type cancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+type listResponse struct {
+	Workflows  []*workflowResponse `json:"workflows"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// handleStart starts the workflow of a new order with the data in the
+// request body and returns it.
+func (s *server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req startRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.OrderID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("order_id is required"))
+		return
+	}
+	if strings.Contains(req.OrderID, "/") {
+		// reserved for the order IDs of child workflows
+		writeError(w, http.StatusBadRequest, errors.New("order_id cannot contain /"))
+		return
+	}
+
+	err := s.orchestrator.StartWorkflow(r.Context(), req.OrderID, req.Data)
+	switch {
+	case errors.Is(err, domain.ErrWorkflowExists):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	details, err := s.orchestrator.GetWorkflow(r.Context(), req.OrderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", "/workflows/"+req.OrderID)
+	writeJSON(w, http.StatusCreated, detailsResponse(details))
+}
+
+// handleGet returns the workflow of an order, with its step outputs, order
+// data and agents.
+func (s *server) handleGet(w http.ResponseWriter, r *http.Request) {
+	details, err := s.orchestrator.GetWorkflow(r.Context(), r.PathValue("orderID"))
+	switch {
+	case errors.Is(err, domain.ErrWorkflowNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, detailsResponse(details))
+	}
+}
+
+// handleCancel cancels a running workflow and returns it. The optional reason
+// in the request body is recorded in its history.
+func (s *server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	var req cancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	orderID := r.PathValue("orderID")
+	err := s.orchestrator.CancelWorkflow(r.Context(), orderID, req.Reason)
+	switch {
+	case errors.Is(err, domain.ErrWorkflowNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, domain.ErrNotAllowed):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	details, err := s.orchestrator.GetWorkflow(r.Context(), orderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, detailsResponse(details))
+}
+
+// handleList returns a page of workflows, newest first. They can be filtered
+// with the status (comma-separated), created_after and created_before (RFC
+// 3339) parameters; the next page is requested with the cursor of the last.
+func (s *server) handleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	states, next, err := s.orchestrator.ListWorkflows(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := listResponse{Workflows: []*workflowResponse{}}
+	if next != nil {
+		resp.NextCursor = next.Encode()
+	}
+	for _, state := range states {
+		resp.Workflows = append(resp.Workflows, stateResponse(state))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func parseFilter(r *http.Request) (domain.WorkflowFilter, error) {
+	q := r.URL.Query()
+	filter := domain.WorkflowFilter{Limit: defaultPageSize}
+	if v := q.Get("status"); v != "" {
+		for _, status := range strings.Split(v, ",") {
+			filter.Statuses = append(filter.Statuses, domain.WorkflowStatus(status))
+		}
+	}
+	for name, t := range map[string]*time.Time{"created_after": &filter.CreatedAfter, "created_before": &filter.CreatedBefore} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*t = parsed
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := domain.DecodeWorkflowCursor(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = cursor
+	}
+	return filter, nil
+}
+
+func stateResponse(state *domain.WorkflowState) *workflowResponse {
+	return &workflowResponse{
+		OrderID:              state.OrderID,
+		Workflow:             state.Workflow,
+		WorkflowVersion:      state.WorkflowVersion,
+		ParentOrderID:        state.ParentOrderID,
+		ParentStep:           state.ParentStep,
+		Status:               state.Status,
+		CurrentStep:          state.CurrentStep,
+		ActiveSteps:          nonNil(state.ActiveSteps),
+		CompletedSteps:       nonNil(state.CompletedSteps),
+		PendingCompensations: state.PendingCompensations,
+		CompensationFailure:  state.CompensationFailure,
+		PausedBy:             state.PausedBy,
+		CreatedAt:            state.CreatedAt,
+		UpdatedAt:            state.UpdatedAt,
+	}
+}
+
+func detailsResponse(details *usecases.WorkflowDetails) *workflowResponse {
+	resp := stateResponse(details.State)
+	resp.StepOutputs = details.State.StepOutputs
+	resp.Agents = details.Agents
+	if details.Order != nil {
+		resp.OrderStatus = details.Order.Status
+		resp.Data = details.Order.Data
+	}
+	return resp
+}
+
+// nonNil returns steps, or an empty slice for nil so it is encoded as [].
+func nonNil(steps []domain.Step) []domain.Step {
+	if steps == nil {
+		return []domain.Step{}
+	}
+	return steps
+}
@@ -0,0 +1,58 @@
+package definitions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.yaml.in/yaml/v2"
+)
+
+// LoadFile reads a workflow definition from a .yaml, .yml or .json file. A
+// definition without a version is version 1.
+func LoadFile(path string) (*domain.WorkflowDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow definition %s: %w", path, err)
+	}
+
+	def := &domain.WorkflowDefinition{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, def)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(def)
+	default:
+		return nil, fmt.Errorf("unsupported workflow definition format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow definition %s: %w", path, err)
+	}
+
+	if def.Version == 0 {
+		def.Version = 1
+	}
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid workflow definition %s: %w", path, err)
+	}
+	return def, nil
+}
+
+// LoadFiles reads every definition in paths, in order.
+func LoadFiles(paths []string) ([]*domain.WorkflowDefinition, error) {
+	defs := make([]*domain.WorkflowDefinition, 0, len(paths))
+	for _, path := range paths {
+		def, err := LoadFile(strings.TrimSpace(path))
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
@@ -0,0 +1,66 @@
+package executors
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// Registry maps steps and compensation steps to the implementations that
+// perform them. Implementations are registered at wiring time.
+type Registry struct {
+	mu            sync.RWMutex
+	steps         map[domain.Step]domain.StepExecutor
+	compensations map[domain.CompensationStep]domain.Compensator
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		steps:         make(map[domain.Step]domain.StepExecutor),
+		compensations: make(map[domain.CompensationStep]domain.Compensator),
+	}
+}
+
+func (r *Registry) RegisterStep(step domain.Step, exec domain.StepExecutor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps[step] = exec
+}
+
+func (r *Registry) RegisterCompensation(comp domain.CompensationStep, c domain.Compensator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compensations[comp] = c
+}
+
+func (r *Registry) Step(step domain.Step) (domain.StepExecutor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	exec, ok := r.steps[step]
+	return exec, ok
+}
+
+func (r *Registry) Compensation(comp domain.CompensationStep) (domain.Compensator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.compensations[comp]
+	return c, ok
+}
+
+// Check reports the first step or compensation of def that has nothing
+// registered for it.
+func (r *Registry) Check(def *domain.WorkflowDefinition) error {
+	for _, s := range def.ExecutableSteps() {
+		if _, ok := r.Step(s.Name); !ok {
+			return fmt.Errorf("no executor registered for step %s", s.Name)
+		}
+		if s.Compensation == "" {
+			continue
+		}
+		if _, ok := r.Compensation(s.Compensation); !ok {
+			return fmt.Errorf("no compensator registered for %s", s.Compensation)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+	workflowv1 "github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/proto/workflow/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toWorkflow(state *domain.WorkflowState) *workflowv1.Workflow {
+	w := &workflowv1.Workflow{
+		OrderId:              state.OrderID,
+		Workflow:             state.Workflow,
+		WorkflowVersion:      int32(state.WorkflowVersion),
+		ParentOrderId:        state.ParentOrderID,
+		ParentStep:           string(state.ParentStep),
+		Status:               string(state.Status),
+		CurrentStep:          string(state.CurrentStep),
+		ActiveSteps:          toStrings(state.ActiveSteps),
+		CompletedSteps:       toStrings(state.CompletedSteps),
+		PendingCompensations: toStrings(state.PendingCompensations),
+		CreatedAt:            timestamppb.New(state.CreatedAt),
+		UpdatedAt:            timestamppb.New(state.UpdatedAt),
+	}
+	if f := state.CompensationFailure; f != nil {
+		w.CompensationFailure = &workflowv1.CompensationFailure{
+			Step:         string(f.Step),
+			Compensation: string(f.Compensation),
+			Error:        f.Error,
+			FailedAt:     timestamppb.New(f.FailedAt),
+		}
+	}
+	return w
+}
+
+func toGetResponse(details *usecases.WorkflowDetails) (*workflowv1.GetWorkflowResponse, error) {
+	resp := &workflowv1.GetWorkflowResponse{
+		Workflow: toWorkflow(details.State),
+		Agents:   details.Agents,
+	}
+	if len(details.State.StepOutputs) > 0 {
+		resp.StepOutputs = make(map[string]*structpb.Struct, len(details.State.StepOutputs))
+		for step, output := range details.State.StepOutputs {
+			s, err := toStruct(output)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to encode output of step %s: %v", step, err)
+			}
+			resp.StepOutputs[string(step)] = s
+		}
+	}
+	if details.Order != nil {
+		resp.OrderStatus = details.Order.Status
+		data, err := toStruct(details.Order.Data)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode order data: %v", err)
+		}
+		resp.Data = data
+	}
+	return resp, nil
+}
+
+func toTransition(t *domain.WorkflowTransition) *workflowv1.Transition {
+	return &workflowv1.Transition{
+		Id:         t.ID,
+		FromStep:   string(t.FromStep),
+		ToStep:     string(t.ToStep),
+		FromStatus: string(t.FromStatus),
+		ToStatus:   string(t.ToStatus),
+		Cause:      t.Cause,
+		Error:      t.Error,
+		Worker:     t.Worker,
+		CreatedAt:  timestamppb.New(t.CreatedAt),
+	}
+}
+
+// toStruct converts m through JSON, so values of any JSON-encodable type are
+// accepted, not only those structpb.NewStruct knows.
+func toStruct(m map[string]any) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func toStrings(steps []domain.Step) []string {
+	if steps == nil {
+		return nil
+	}
+	out := make([]string, len(steps))
+	for i, step := range steps {
+		out[i] = string(step)
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+	workflowv1 "github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/proto/workflow/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	// watchInterval is how often WatchWorkflow polls the workflow history.
+	watchInterval = 500 * time.Millisecond
+)
+
+type server struct {
+	workflowv1.UnimplementedWorkflowServiceServer
+	orchestrator *usecases.Orchestrator
+}
+
+// Register adds the workflow service of orchestrator and server reflection
+// to s.
+func Register(s *grpc.Server, orchestrator *usecases.Orchestrator) {
+	workflowv1.RegisterWorkflowServiceServer(s, &server{orchestrator: orchestrator})
+	reflection.Register(s)
+}
+
+func (s *server) StartWorkflow(ctx context.Context, req *workflowv1.StartWorkflowRequest) (*workflowv1.StartWorkflowResponse, error) {
+	if req.GetOrderId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+	if strings.Contains(req.GetOrderId(), "/") {
+		// reserved for the order IDs of child workflows
+		return nil, status.Error(codes.InvalidArgument, "order_id cannot contain /")
+	}
+
+	if err := s.orchestrator.StartWorkflow(ctx, req.GetOrderId(), req.GetData().AsMap()); err != nil {
+		return nil, toStatus(err)
+	}
+	details, err := s.orchestrator.GetWorkflow(ctx, req.GetOrderId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &workflowv1.StartWorkflowResponse{Workflow: toWorkflow(details.State)}, nil
+}
+
+func (s *server) GetWorkflow(ctx context.Context, req *workflowv1.GetWorkflowRequest) (*workflowv1.GetWorkflowResponse, error) {
+	details, err := s.orchestrator.GetWorkflow(ctx, req.GetOrderId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toGetResponse(details)
+}
+
+func (s *server) ListWorkflows(ctx context.Context, req *workflowv1.ListWorkflowsRequest) (*workflowv1.ListWorkflowsResponse, error) {
+	filter := domain.WorkflowFilter{Limit: defaultPageSize}
+	for _, st := range req.GetStatuses() {
+		filter.Statuses = append(filter.Statuses, domain.WorkflowStatus(st))
+	}
+	if req.CreatedAfter != nil {
+		filter.CreatedAfter = req.GetCreatedAfter().AsTime()
+	}
+	if req.CreatedBefore != nil {
+		filter.CreatedBefore = req.GetCreatedBefore().AsTime()
+	}
+	if size := req.GetPageSize(); size != 0 {
+		if size < 0 || size > maxPageSize {
+			return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxPageSize)
+		}
+		filter.Limit = int(size)
+	}
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := domain.DecodeWorkflowCursor(token)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.After = cursor
+	}
+
+	states, next, err := s.orchestrator.ListWorkflows(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &workflowv1.ListWorkflowsResponse{}
+	if next != nil {
+		resp.NextPageToken = next.Encode()
+	}
+	for _, state := range states {
+		resp.Workflows = append(resp.Workflows, toWorkflow(state))
+	}
+	return resp, nil
+}
+
+func (s *server) CancelWorkflow(ctx context.Context, req *workflowv1.CancelWorkflowRequest) (*workflowv1.CancelWorkflowResponse, error) {
+	if err := s.orchestrator.CancelWorkflow(ctx, req.GetOrderId(), req.GetReason()); err != nil {
+		return nil, toStatus(err)
+	}
+	details, err := s.orchestrator.GetWorkflow(ctx, req.GetOrderId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &workflowv1.CancelWorkflowResponse{Workflow: toWorkflow(details.State)}, nil
+}
+
+func (s *server) SignalWorkflow(ctx context.Context, req *workflowv1.SignalWorkflowRequest) (*workflowv1.SignalWorkflowResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	var payload map[string]any
+	if req.Payload != nil {
+		payload = req.GetPayload().AsMap()
+	}
+	if err := s.orchestrator.SignalWorkflow(ctx, req.GetOrderId(), req.GetName(), payload); err != nil {
+		return nil, toStatus(err)
+	}
+	return &workflowv1.SignalWorkflowResponse{}, nil
+}
+
+// WatchWorkflow sends the transitions the workflow already made, then each
+// new one as it is recorded, and returns when the workflow is final.
+func (s *server) WatchWorkflow(req *workflowv1.WatchWorkflowRequest, stream grpc.ServerStreamingServer[workflowv1.WatchWorkflowResponse]) error {
+	err := s.orchestrator.WatchWorkflow(stream.Context(), req.GetOrderId(), watchInterval, func(t *domain.WorkflowTransition, state *domain.WorkflowState) error {
+		return stream.Send(&workflowv1.WatchWorkflowResponse{
+			Transition: toTransition(t),
+			Workflow:   toWorkflow(state),
+		})
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// toStatus maps the errors of the orchestrator to gRPC status errors.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWorkflowNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrWorkflowExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrSignalNotExpected), errors.Is(err, domain.ErrNotAllowed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -6,146 +6,278 @@ import (
 	"fmt"
 	"math/rand"
 	"sync/atomic"
+	"time"
 
 	"github.com/hibiken/asynq"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/executors"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/metrics"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/tracing"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/mocks"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-var (
-	logger      *zap.Logger
-	failureProb atomic.Value
-)
+// Handlers process the queue tasks of an Orchestrator, dispatching steps and
+// compensations to the executors and compensators of a registry.
+type Handlers struct {
+	orchestrator  *usecases.Orchestrator
+	registry      *executors.Registry
+	steps         domain.StepExecutionRepo
+	compensations domain.CompensationExecutionRepo
+	logger        *zap.Logger
+	tracer        trace.Tracer
+	attempt       func(context.Context) queue.Attempt
+	failureProb   atomic.Value
+}
 
-func init() {
-	var err error
-	logger, err = zap.NewProduction()
-	if err != nil {
-		panic(fmt.Sprintf("failed to init logger: %v", err))
+func New(orchestrator *usecases.Orchestrator, registry *executors.Registry, steps domain.StepExecutionRepo, compensations domain.CompensationExecutionRepo) *Handlers {
+	h := &Handlers{
+		orchestrator:  orchestrator,
+		registry:      registry,
+		steps:         steps,
+		compensations: compensations,
+		logger:        orchestrator.Logger(),
+		tracer:        orchestrator.Tracer(),
+		attempt:       queue.TaskAttempt,
 	}
-	failureProb.Store(0.0)
+	h.failureProb.Store(0.0)
+	return h
+}
+
+func (h *Handlers) SetFailureProbability(prob float64) {
+	h.failureProb.Store(prob)
+}
+
+// SetAttemptFunc replaces how h learns the attempt of the task it handles,
+// which is asynq's by default, for a queue that runs the tasks itself.
+func (h *Handlers) SetAttemptFunc(attempt func(context.Context) queue.Attempt) {
+	h.attempt = attempt
 }
 
-func SetFailureProbability(prob float64) {
-	failureProb.Store(prob)
+// Register routes the task types of the orchestrator to h.
+func (h *Handlers) Register(mux *asynq.ServeMux) {
+	mux.HandleFunc("step", h.HandleStep)
+	mux.HandleFunc("timer", h.HandleTimer)
+	mux.HandleFunc("compensation", h.HandleCompensation)
 }
 
-func HandleStep(ctx context.Context, t *asynq.Task) error {
+func (h *Handlers) HandleStep(ctx context.Context, t *asynq.Task) error {
 	var payload queue.StepPayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
 		return fmt.Errorf("failed to unmarshal step payload: %w", err)
 	}
 
-	spanCtx, span := tracing.Tracer.Start(ctx, "handle_step."+string(payload.Step))
+	spanCtx, span := h.tracer.Start(ctx, "handle_step."+string(payload.Step))
 	defer span.End()
 
-	logger.Info("Processing step",
+	retried := h.attempt(ctx).Retried
+	h.logger.Info("Processing step",
 		zap.String("order_id", payload.OrderID),
-		zap.String("step", string(payload.Step)))
+		zap.String("step", string(payload.Step)),
+		zap.Int("attempt", retried+1))
 
-	cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
+	handled, err := h.orchestrator.RunEngineStep(spanCtx, payload.OrderID, payload.Step)
+	if err != nil {
+		return fmt.Errorf("failed to run step: %w", err)
+	}
+	if handled {
+		return nil
+	}
 
-	stepRepo := repositories.NewStepExecutionRepo(db)
-	dedupeKey := fmt.Sprintf("%s_%s", payload.OrderID, payload.Step)
-	executed, result, err := stepRepo.IsExecuted(spanCtx, dedupeKey)
+	dedupeKey := domain.ExecutionKey(payload.OrderID, string(payload.Step))
+	exec, err := h.steps.GetExecution(spanCtx, dedupeKey)
 	if err != nil {
 		return fmt.Errorf("failed to check step execution: %w", err)
 	}
-	if executed {
-		logger.Info("Step already executed",
+	if exec != nil {
+		h.logger.Info("Step already executed",
 			zap.String("order_id", payload.OrderID),
 			zap.String("step", string(payload.Step)),
-			zap.String("result", result))
-		if result == "success" {
-			return usecases.NextStep(spanCtx, payload.OrderID, payload.Step)
+			zap.String("result", exec.Result))
+		if exec.Result == "success" || exec.Result == "skipped" {
+			return h.orchestrator.NextStep(spanCtx, payload.OrderID, payload.Step, exec.Output)
 		}
-		return fmt.Errorf("step previously failed: %s", result)
+		// the step failed for good; make sure the rollback it triggered ran
+		return h.failStep(spanCtx, payload, domain.Terminal(fmt.Errorf("step previously %s", exec.Result)))
+	}
+
+	runnable, err := h.orchestrator.StepRunnable(spanCtx, payload.OrderID, payload.Step)
+	if err != nil {
+		return fmt.Errorf("failed to check step: %w", err)
+	}
+	if !runnable {
+		// resuming a paused workflow enqueues the step again
+		h.logger.Info("Step is not to run now, not running it",
+			zap.String("order_id", payload.OrderID),
+			zap.String("step", string(payload.Step)))
+		return nil
+	}
+
+	rootOrderID, err := h.orchestrator.RootOrderID(spanCtx, payload.OrderID)
+	if err != nil {
+		return err
 	}
 
 	var chaosErr error
-	if rand.Float64() < failureProb.Load().(float64) {
-		chaosErr = fmt.Errorf("injected failure for step %s", payload.Step)
-	}
-
-	var stepErr error
-	switch payload.Step {
-	case domain.StepReserveSlot:
-		_, stepErr = mocks.ReserveSlot(payload.OrderID)
-	case domain.StepAssignAgent:
-		_, stepErr = mocks.AssignAgent(spanCtx, payload.OrderID)
-	case domain.StepNotifyCustomer:
-		stepErr = mocks.NotifyCustomer(payload.OrderID)
-	default:
-		stepErr = fmt.Errorf("unknown step: %s", payload.Step)
-	}
-
-	result = "success"
-	if stepErr != nil || chaosErr != nil {
-		result = "failed"
-		if err := stepRepo.SaveExecution(spanCtx, dedupeKey, result); err != nil {
-			return fmt.Errorf("failed to save step execution: %w", err)
-		}
+	if rand.Float64() < h.failureProb.Load().(float64) {
+		// terminal, so injected failures still exercise compensation
+		chaosErr = domain.Terminal(fmt.Errorf("injected failure for step %s", payload.Step))
+	}
+
+	var (
+		output  map[string]any
+		stepErr error
+	)
+	execCtx := spanCtx
+	if payload.Retry != nil && payload.Retry.Timeout > 0 {
+		// also bounds attempts of tasks enqueued without queue.RetryOptions
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(spanCtx, time.Duration(payload.Retry.Timeout))
+		defer cancel()
+	}
+	if executor, ok := h.registry.Step(payload.Step); ok {
+		output, stepErr = executor.Execute(execCtx, rootOrderID)
+	} else {
+		stepErr = domain.Terminal(fmt.Errorf("no executor registered for step %s", payload.Step))
+	}
+
+	if err := coalesceErr(stepErr, chaosErr); err != nil {
 		metrics.StepFailure.WithLabelValues(string(payload.Step)).Inc()
-		if err := usecases.Compensate(spanCtx, payload.OrderID, payload.Step); err != nil {
-			return fmt.Errorf("failed to compensate: %w", err)
+		kind := domain.ErrorKindOf(err)
+		if kind == domain.ErrorTransient && !h.attempt(ctx).Last() {
+			h.logger.Warn("Step failed, will retry",
+				zap.String("order_id", payload.OrderID),
+				zap.String("step", string(payload.Step)),
+				zap.Error(err))
+			return fmt.Errorf("step failed: %w", err)
+		}
+
+		// recorded before compensating, so a retry of this task after a
+		// failed rollback goes straight back to compensation
+		result := "failed"
+		if kind == domain.ErrorRejected {
+			result = "rejected"
 		}
-		return fmt.Errorf("step failed: %w", coalesceErr(stepErr, chaosErr))
+		if err := h.steps.SaveExecution(spanCtx, &domain.StepExecution{DedupeKey: dedupeKey, Result: result}); err != nil {
+			return fmt.Errorf("failed to save step execution: %w", err)
+		}
+		return h.failStep(spanCtx, payload, err)
 	}
 
-	if err := stepRepo.SaveExecution(spanCtx, dedupeKey, result); err != nil {
+	exec = &domain.StepExecution{DedupeKey: dedupeKey, Result: "success", Output: output}
+	if err := h.steps.SaveExecution(spanCtx, exec); err != nil {
 		return fmt.Errorf("failed to save step execution: %w", err)
 	}
 
+	h.logger.Info("Step succeeded",
+		zap.String("order_id", payload.OrderID),
+		zap.String("step", string(payload.Step)),
+		zap.Any("output", output))
 	metrics.StepSuccess.WithLabelValues(string(payload.Step)).Inc()
-	if err := usecases.NextStep(spanCtx, payload.OrderID, payload.Step); err != nil {
+	if err := h.orchestrator.NextStep(spanCtx, payload.OrderID, payload.Step, output); err != nil {
 		return fmt.Errorf("failed to enqueue next step: %w", err)
 	}
 
 	return nil
 }
 
-func HandleCompensation(ctx context.Context, t *asynq.Task) error {
+// failStep compensates the workflow of a step that failed for good and tells
+// asynq not to retry the step. Compensating a rolled back workflow is a no-op.
+func (h *Handlers) failStep(ctx context.Context, payload queue.StepPayload, stepErr error) error {
+	h.logger.Error("Step failed, compensating",
+		zap.String("order_id", payload.OrderID),
+		zap.String("step", string(payload.Step)),
+		zap.String("kind", string(domain.ErrorKindOf(stepErr))),
+		zap.Error(stepErr))
+	if err := h.orchestrator.Compensate(ctx, payload.OrderID, payload.Step, stepErr); err != nil {
+		return fmt.Errorf("failed to compensate: %w", err)
+	}
+	return fmt.Errorf("step failed: %w: %w", stepErr, asynq.SkipRetry)
+}
+
+func (h *Handlers) HandleTimer(ctx context.Context, t *asynq.Task) error {
+	var payload queue.StepPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal timer payload: %w", err)
+	}
+
+	spanCtx, span := h.tracer.Start(ctx, "handle_timer."+string(payload.Step))
+	defer span.End()
+
+	if err := h.orchestrator.FireTimer(spanCtx, payload.OrderID, payload.Step); err != nil {
+		return fmt.Errorf("failed to fire timer: %w", err)
+	}
+	return nil
+}
+
+func (h *Handlers) HandleCompensation(ctx context.Context, t *asynq.Task) error {
 	var payload queue.StepPayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
 		return fmt.Errorf("failed to unmarshal compensation payload: %w", err)
 	}
 
-	spanCtx, span := tracing.Tracer.Start(ctx, "handle_compensation."+string(payload.Step))
+	spanCtx, span := h.tracer.Start(ctx, "handle_compensation."+string(payload.Step))
 	defer span.End()
 
-	logger.Info("Processing compensation",
+	h.logger.Info("Processing compensation",
 		zap.String("order_id", payload.OrderID),
 		zap.String("compensation", string(payload.Step)))
 
-	var err error
-	switch domain.CompensationStep(payload.Step) {
-	case domain.CompReleaseSlot:
-		err = mocks.ReleaseSlot(payload.OrderID)
-	case domain.CompUnassignAgent:
-		err = mocks.UnassignAgent(spanCtx, payload.OrderID)
-	case domain.CompCancelNotification:
-		err = mocks.CancelNotification(payload.OrderID)
-	default:
-		err = fmt.Errorf("unknown compensation step: %s", payload.Step)
+	// claimed before running, so an attempt that crashed after compensating
+	// is known to have maybe run; compensators must accept running again
+	exec := &domain.CompensationExecution{
+		DedupeKey:    domain.ExecutionKey(payload.OrderID, string(payload.Step)),
+		OrderID:      payload.OrderID,
+		Compensation: domain.CompensationStep(payload.Step),
+	}
+	claimed, err := h.compensations.ClaimCompensation(spanCtx, exec)
+	if err != nil {
+		return fmt.Errorf("failed to claim compensation execution: %w", err)
+	}
+	if !claimed {
+		h.logger.Info("Compensation already executed",
+			zap.String("order_id", payload.OrderID),
+			zap.String("compensation", string(payload.Step)))
+		return h.orchestrator.CompensationSucceeded(spanCtx, payload.OrderID, domain.CompensationStep(payload.Step))
 	}
 
+	rootOrderID, err := h.orchestrator.RootOrderID(spanCtx, payload.OrderID)
 	if err != nil {
-		metrics.CompensationTotal.WithLabelValues(string(payload.Step)).Inc()
-		return fmt.Errorf("compensation failed: %w", err)
+		return err
+	}
+	if c, ok := h.registry.Compensation(domain.CompensationStep(payload.Step)); ok {
+		err = c.Compensate(spanCtx, rootOrderID)
+	} else {
+		err = domain.Terminal(fmt.Errorf("no compensator registered for %s", payload.Step))
 	}
 
+	exec.Result = "success"
+	if err != nil {
+		exec.Result = "failed"
+		exec.LastError = err.Error()
+	}
+	if saveErr := h.compensations.SaveCompensationAttempt(spanCtx, exec); saveErr != nil {
+		return fmt.Errorf("failed to save compensation execution: %w", saveErr)
+	}
 	metrics.CompensationTotal.WithLabelValues(string(payload.Step)).Inc()
-	return nil
+
+	if err != nil {
+		h.logger.Warn("Compensation failed",
+			zap.String("order_id", payload.OrderID),
+			zap.String("compensation", string(payload.Step)),
+			zap.Int("attempts", exec.Attempts),
+			zap.Error(err))
+		if domain.ErrorKindOf(err) == domain.ErrorTransient && !h.attempt(ctx).Last() {
+			return fmt.Errorf("compensation failed: %w", err)
+		}
+		metrics.CompensationFailed.WithLabelValues(string(payload.Step)).Inc()
+		if parkErr := h.orchestrator.CompensationFailed(spanCtx, payload.OrderID, domain.CompensationStep(payload.Step), err); parkErr != nil {
+			return fmt.Errorf("failed to park rollback: %w", parkErr)
+		}
+		return fmt.Errorf("compensation failed: %w: %w", err, asynq.SkipRetry)
+	}
+	return h.orchestrator.CompensationSucceeded(spanCtx, payload.OrderID, domain.CompensationStep(payload.Step))
 }
 
 func coalesceErr(errs ...error) error {
@@ -29,10 +29,17 @@ var (
 		},
 		[]string{"compensation_step"},
 	)
+	CompensationFailed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workflow_compensation_failed_total",
+			Help: "Total number of rollbacks parked after a compensation failed for good",
+		},
+		[]string{"compensation_step"},
+	)
 )
 
 func InitMetrics() {
-	prometheus.MustRegister(StepSuccess, StepFailure, CompensationTotal)
+	prometheus.MustRegister(StepSuccess, StepFailure, CompensationTotal, CompensationFailed)
 }
 
 func MetricsHandler() http.Handler {
@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,8 +13,9 @@ import (
 )
 
 type StepPayload struct {
-	OrderID string      `json:"order_id"`
-	Step    domain.Step `json:"step"`
+	OrderID string              `json:"order_id"`
+	Step    domain.Step         `json:"step"`
+	Retry   *domain.RetryPolicy `json:"retry,omitempty"` // retry policy of the step, if it declares one
 }
 
 func NewQueueClient() *asynq.Client {
@@ -24,26 +26,128 @@ func NewQueueClient() *asynq.Client {
 func NewQueueServer() *asynq.Server {
 	addr := config.Load().RedisAddr
 	return asynq.NewServer(asynq.RedisClientOpt{Addr: addr}, asynq.Config{
-		Concurrency: 10,
-		RetryDelayFunc: func(n int, err error, _ *asynq.Task) time.Duration {
-			return time.Duration(1<<n) * time.Second
-		},
+		Concurrency:    10,
+		RetryDelayFunc: RetryDelay,
 	})
 }
 
+// RetryDelay applies the retry policy carried in the payload of t, and falls
+// back to the delays of domain.DefaultRetryPolicy.
+func RetryDelay(n int, _ error, t *asynq.Task) time.Duration {
+	var payload StepPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err == nil && payload.Retry != nil {
+		return payload.Retry.RetryDelay(n)
+	}
+	return domain.DefaultRetryPolicy.RetryDelay(n)
+}
+
+// RetryOptions returns the asynq options that enforce the attempt limit and
+// per-attempt timeout of p. It returns nil for a nil policy.
+func RetryOptions(p *domain.RetryPolicy) []asynq.Option {
+	if p == nil {
+		return nil
+	}
+	var opts []asynq.Option
+	if p.MaxAttempts > 0 {
+		opts = append(opts, asynq.MaxRetry(p.MaxAttempts-1))
+	}
+	if p.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(time.Duration(p.Timeout)))
+	}
+	return opts
+}
+
+// Attempt is how far the task being handled is in its retries.
+type Attempt struct {
+	Retried  int // times the task was retried before this attempt
+	MaxRetry int
+}
+
+// Last reports whether the task will not be retried if this attempt fails.
+func (a Attempt) Last() bool {
+	return a.Retried >= a.MaxRetry
+}
+
+// TaskAttempt returns the attempt of the asynq task being handled with ctx. An
+// attempt asynq does not report on counts as the last.
+func TaskAttempt(ctx context.Context) Attempt {
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return Attempt{}
+	}
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return Attempt{Retried: retried, MaxRetry: maxRetry}
+}
+
 func NewServeMux() *asynq.ServeMux {
 	return asynq.NewServeMux()
 }
 
-func EnqueueStep(ctx context.Context, client *asynq.Client, taskType string, payload StepPayload) error {
+func EnqueueStep(ctx context.Context, client *asynq.Client, taskType string, payload StepPayload, opts ...asynq.Option) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marsahl paylaod: %w", err)
 	}
 	task := asynq.NewTask(taskType, data)
-	_, err = client.EnqueueContext(ctx, task)
+	_, err = client.EnqueueContext(ctx, task, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to enqueue task: %w", err)
 	}
 	return nil
+}
+
+// Publish enqueues an outbox message. Messages without a task ID of their own
+// get one derived from the message, so publishing a message again while its
+// task is still queued is a no-op.
+func Publish(ctx context.Context, client *asynq.Client, msg *domain.OutboxMessage) error {
+	taskID := msg.TaskID
+	if taskID == "" {
+		taskID = fmt.Sprintf("outbox:%d", msg.ID)
+	}
+	opts := []asynq.Option{asynq.TaskID(taskID)}
+	if !msg.ProcessAt.IsZero() {
+		opts = append(opts, asynq.ProcessAt(msg.ProcessAt))
+	}
+	var payload StepPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload of outbox message %d: %w", msg.ID, err)
+	}
+	opts = append(opts, RetryOptions(payload.Retry)...)
+
+	_, err := client.EnqueueContext(ctx, asynq.NewTask(msg.TaskType, msg.Payload), opts...)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("failed to publish outbox message %d: %w", msg.ID, err)
+	}
+	return nil
+}
+
+type asynqPublisher struct {
+	client *asynq.Client
+}
+
+// NewPublisher returns a domain.TaskPublisher that publishes outbox messages
+// to asynq with client.
+func NewPublisher(client *asynq.Client) domain.TaskPublisher {
+	return &asynqPublisher{client: client}
+}
+
+func (p *asynqPublisher) Publish(ctx context.Context, msg *domain.OutboxMessage) error {
+	return Publish(ctx, p.client, msg)
+}
+
+// TimerTaskID is the task ID of the timer of step, which keeps a timer from
+// being scheduled twice.
+func TimerTaskID(orderID string, step domain.Step) string {
+	return fmt.Sprintf("timer:%s:%s", orderID, step)
+}
+
+// EnqueueTimer schedules the "timer" task that fires a timer step at wakeAt.
+// The task ID is derived from the order and step, so scheduling a timer that
+// is already pending is a no-op.
+func EnqueueTimer(ctx context.Context, client *asynq.Client, payload StepPayload, wakeAt time.Time) error {
+	err := EnqueueStep(ctx, client, "timer", payload, asynq.ProcessAt(wakeAt), asynq.TaskID(TimerTaskID(payload.OrderID, payload.Step)))
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
 }
\ No newline at end of file
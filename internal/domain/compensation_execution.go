@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// CompensationExecution is the log entry of one compensation for an order.
+// Every attempt updates the same entry, so Attempts counts the runs and
+// Result is the outcome of the last one. Once a compensation succeeded it
+// stays successful.
+type CompensationExecution struct {
+	DedupeKey    string
+	OrderID      string
+	Compensation CompensationStep
+	Result       string // in_progress while an attempt runs, then success or failed
+	Attempts     int
+	LastError    string
+	StartedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+type CompensationExecutionRepo interface {
+	GetCompensationExecution(ctx context.Context, dedupeKey string) (*CompensationExecution, error)
+	// ClaimCompensation records a new attempt of exec.Compensation as in
+	// progress, before it runs, and sets exec.Attempts to the number of
+	// attempts so far. It reports false, recording nothing, if the
+	// compensation already succeeded.
+	ClaimCompensation(ctx context.Context, exec *CompensationExecution) (bool, error)
+	// SaveCompensationAttempt records the result of the attempt last claimed,
+	// or of a first attempt if none was, and sets exec.Attempts. A failure
+	// never replaces a recorded success.
+	SaveCompensationAttempt(ctx context.Context, exec *CompensationExecution) error
+	GetCompensationExecutionsByOrderID(ctx context.Context, orderID string) ([]*CompensationExecution, error)
+	// DeleteCompensationExecution forgets the attempts of dedupeKey, so the
+	// compensation runs again in a later rollback.
+	DeleteCompensationExecution(ctx context.Context, dedupeKey string) error
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrWorkflowNotFound  = errors.New("workflow not found")
+	ErrWorkflowExists    = errors.New("workflow already exists")
+	ErrSignalNotExpected = errors.New("workflow is not waiting for this signal")
+	ErrVersionConflict   = errors.New("workflow was changed concurrently")
+	ErrNotAllowed        = errors.New("operation not allowed")
+)
+
+// VersionConflictError is returned when a workflow state is saved from a
+// version that is no longer the stored one. It matches ErrVersionConflict.
+type VersionConflictError struct {
+	OrderID string
+	Version int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%v: order %s is no longer at version %d", ErrVersionConflict, e.OrderID, e.Version)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
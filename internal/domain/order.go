@@ -4,7 +4,8 @@ import "time"
 
 type Order struct {
 	ID        string
-	Status    string // pending, fulfilled, failed
+	Status    string         // pending, fulfilled, failed
+	Data      map[string]any // order attributes supplied when the workflow was started
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -5,4 +5,4 @@ import "context"
 type OrderRepo interface {
 	SaveOrder(ctx context.Context, order *Order) error
 	GetOrderByID(ctx context.Context, orderID string) (*Order, error)
-}
\ No newline at end of file
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// OutboxMessage is a queue task recorded in the same transaction as the state
+// change that produced it. The outbox relay publishes it once the transaction
+// has committed.
+type OutboxMessage struct {
+	ID        int64
+	TaskType  string
+	Payload   []byte
+	TaskID    string    // optional queue task ID, for tasks that must not be scheduled twice
+	ProcessAt time.Time // zero to process right away
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+type OutboxRepo interface {
+	AddMessage(ctx context.Context, msg *OutboxMessage) error
+	// GetUnsentMessages returns up to limit unsent messages, oldest first.
+	// Within a transaction the messages stay locked, and skipped by other
+	// relays, until it ends.
+	GetUnsentMessages(ctx context.Context, limit int) ([]*OutboxMessage, error)
+	MarkSent(ctx context.Context, ids []int64) error
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// WorkflowPause holds the executor steps of an operator-chosen name, or every
+// executor step when Step is empty. A workflow about to run a held step is
+// paused instead, until the pause is lifted.
+type WorkflowPause struct {
+	Step      Step
+	Reason    string
+	CreatedAt time.Time
+}
+
+// Holds reports whether p holds step.
+func (p *WorkflowPause) Holds(step Step) bool {
+	return p.Step == "" || p.Step == step
+}
+
+// PauseOrigin is what paused a workflow.
+type PauseOrigin string
+
+const (
+	PausedByOperator PauseOrigin = "workflow" // PauseWorkflow paused the workflow itself
+	PausedByStep     PauseOrigin = "step"     // a pause of one step held it
+	PausedByAll      PauseOrigin = "all"      // a pause of every step held it
+)
+
+// Origin returns what a workflow held by p is paused by.
+func (p *WorkflowPause) Origin() PauseOrigin {
+	if p.Step == "" {
+		return PausedByAll
+	}
+	return PausedByStep
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+type PauseRepo interface {
+	// AddPause adds p, or updates the reason of the pause of the same step.
+	AddPause(ctx context.Context, p *WorkflowPause) error
+	// RemovePause lifts the pause of step and reports whether there was one.
+	RemovePause(ctx context.Context, step Step) (bool, error)
+	// GetPauses returns the pauses in force, oldest first.
+	GetPauses(ctx context.Context) ([]*WorkflowPause, error)
+}
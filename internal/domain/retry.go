@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how often and how fast a failed step is retried, and
+// how long a single attempt may run. Unset fields keep the queue defaults.
+type RetryPolicy struct {
+	MaxAttempts int      `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"` // including the first attempt
+	Backoff     string   `json:"backoff,omitempty" yaml:"backoff,omitempty"`           // exponential (with jitter, the default), fixed or linear
+	Delay       Duration `json:"delay,omitempty" yaml:"delay,omitempty"`               // base delay, 1s when unset
+	MaxDelay    Duration `json:"max_delay,omitempty" yaml:"max_delay,omitempty"`
+	Timeout     Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"` // per attempt
+}
+
+// DefaultRetryPolicy bounds the retries of steps whose policy leaves
+// MaxAttempts or MaxDelay unset, instead of the 25 retries of the queue.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, MaxDelay: Duration(time.Minute)}
+
+const (
+	BackoffExponential = "exponential"
+	BackoffFixed       = "fixed"
+	BackoffLinear      = "linear"
+)
+
+func (p RetryPolicy) validate() error {
+	switch p.Backoff {
+	case "", BackoffExponential, BackoffFixed, BackoffLinear:
+	default:
+		return fmt.Errorf("unknown backoff %q", p.Backoff)
+	}
+	switch {
+	case p.MaxAttempts < 0:
+		return fmt.Errorf("max_attempts cannot be negative")
+	case p.Delay < 0 || p.MaxDelay < 0 || p.Timeout < 0:
+		return fmt.Errorf("retry durations cannot be negative")
+	case p.MaxDelay != 0 && p.MaxDelay < p.Delay:
+		return fmt.Errorf("max_delay cannot be shorter than delay")
+	}
+	return nil
+}
+
+// WithDefaults returns a copy of p, DefaultRetryPolicy for nil, with the
+// limits it leaves unset taken from DefaultRetryPolicy.
+func (p *RetryPolicy) WithDefaults() *RetryPolicy {
+	c := DefaultRetryPolicy
+	if p != nil {
+		c = *p
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = max(DefaultRetryPolicy.MaxDelay, c.Delay)
+	}
+	return &c
+}
+
+// RetryDelay returns how long to wait before the next attempt of a step that
+// has already been retried n times.
+func (p RetryPolicy) RetryDelay(n int) time.Duration {
+	base := time.Duration(p.Delay)
+	if base == 0 {
+		base = time.Second
+	}
+	limit := time.Duration(p.MaxDelay)
+	if limit == 0 {
+		limit = time.Duration(1<<63 - 1)
+	}
+
+	d := base
+	switch p.Backoff {
+	case BackoffFixed:
+	case BackoffLinear:
+		if d > limit/time.Duration(n+1) {
+			return limit
+		}
+		d *= time.Duration(n + 1)
+	default:
+		for i := 0; i < n && d < limit; i++ {
+			if d > limit/2 {
+				d = limit
+				break
+			}
+			d *= 2
+		}
+		d = min(d, limit)
+		// spread retries of steps that failed together over [d/2, d]
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+	return min(d, limit)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "fmt"
+
+// SignalDefinition parks a step until the named signal is sent to the order.
+// When Timeout is set and no signal arrives in time, the step fails and the
+// workflow is compensated.
+type SignalDefinition struct {
+	Name    string   `json:"name" yaml:"name"`
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+func (s SignalDefinition) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("signal has no name")
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("signal timeout cannot be negative")
+	}
+	return nil
+}
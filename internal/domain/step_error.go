@@ -0,0 +1,49 @@
+package domain
+
+import "errors"
+
+// ErrorKind tells the engine what to do about a failed step.
+type ErrorKind string
+
+const (
+	ErrorTransient ErrorKind = "transient" // retried until the step runs out of attempts
+	ErrorTerminal  ErrorKind = "terminal"  // retrying cannot help, compensate right away
+	ErrorRejected  ErrorKind = "rejected"  // the service refused the order, compensate right away
+)
+
+// StepError is an error returned by a StepExecutor together with its kind.
+// Errors that are not a StepError are transient.
+type StepError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+func Transient(err error) error {
+	return &StepError{Kind: ErrorTransient, Err: err}
+}
+
+func Terminal(err error) error {
+	return &StepError{Kind: ErrorTerminal, Err: err}
+}
+
+func Rejected(err error) error {
+	return &StepError{Kind: ErrorRejected, Err: err}
+}
+
+// ErrorKindOf returns the kind of the first StepError in err's chain, and
+// ErrorTransient when there is none.
+func ErrorKindOf(err error) ErrorKind {
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		return stepErr.Kind
+	}
+	return ErrorTransient
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type StepExecution struct {
+	DedupeKey  string
+	Result     string // success, skipped, failed, rejected
+	Output     map[string]any
+	ExecutedAt time.Time
+}
+
+// ExecutionKey is the dedupe key of the execution of a step or compensation
+// of an order.
+func ExecutionKey(orderID, name string) string {
+	return orderID + "_" + name
+}
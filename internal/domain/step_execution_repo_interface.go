@@ -3,6 +3,9 @@ package domain
 import "context"
 
 type StepExecutionRepo interface {
-	IsExecuted(ctx context.Context, dedupeKey string) (bool, string, error)
-	SaveExecution(ctx context.Context, dedupeKey, result string) error
+	GetExecution(ctx context.Context, dedupeKey string) (*StepExecution, error)
+	SaveExecution(ctx context.Context, exec *StepExecution) error
+	// DeleteExecution forgets the execution of dedupeKey, so the step runs
+	// again the next time it is processed.
+	DeleteExecution(ctx context.Context, dedupeKey string) error
 }
@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+// StepExecutor runs a forward step for an order. The returned output is
+// recorded with the step result.
+type StepExecutor interface {
+	Execute(ctx context.Context, orderID string) (map[string]any, error)
+}
+
+// Compensator undoes a previously completed step for an order.
+type Compensator interface {
+	Compensate(ctx context.Context, orderID string) error
+}
+
+type StepExecutorFunc func(ctx context.Context, orderID string) (map[string]any, error)
+
+func (f StepExecutorFunc) Execute(ctx context.Context, orderID string) (map[string]any, error) {
+	return f(ctx, orderID)
+}
+
+type CompensatorFunc func(ctx context.Context, orderID string) error
+
+func (f CompensatorFunc) Compensate(ctx context.Context, orderID string) error {
+	return f(ctx, orderID)
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// TaskPublisher hands a task recorded in the outbox to the queue. Publishing
+// the same message twice must not run its task twice.
+type TaskPublisher interface {
+	Publish(ctx context.Context, msg *OutboxMessage) error
+}
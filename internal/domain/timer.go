@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimerDefinition makes a step wait without holding a worker. It either waits
+// Delay after the step starts, or until the time held in the Until field
+// (an RFC 3339 string in "order.<key>" or "steps.<step>.<key>") plus Offset.
+type TimerDefinition struct {
+	Delay  Duration `json:"delay,omitempty" yaml:"delay,omitempty"`
+	Until  string   `json:"until,omitempty" yaml:"until,omitempty"`
+	Offset Duration `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+func (t TimerDefinition) validate() error {
+	switch {
+	case t.Delay != 0 && t.Until != "":
+		return fmt.Errorf("timer cannot have both delay and until")
+	case t.Delay < 0:
+		return fmt.Errorf("timer delay cannot be negative")
+	case t.Until != "":
+		return Condition{Field: t.Until, Op: "exists"}.validate()
+	case t.Offset != 0:
+		return fmt.Errorf("timer offset requires until")
+	case t.Delay == 0:
+		return fmt.Errorf("timer needs a delay or until")
+	}
+	return nil
+}
+
+// WakeAt returns when a timer started at start fires.
+func (t TimerDefinition) WakeAt(start time.Time, in TransitionInput) (time.Time, error) {
+	if t.Until == "" {
+		return start.Add(time.Duration(t.Delay)), nil
+	}
+	v, found, err := lookupField(t.Until, in)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !found {
+		return time.Time{}, fmt.Errorf("timer field %s is not set", t.Until)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("timer field %s is not a time string", t.Until)
+	}
+	until, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timer field %s: %w", t.Until, err)
+	}
+	return until.Add(time.Duration(t.Offset)), nil
+}
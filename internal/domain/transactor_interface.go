@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// Transactor runs fn in a database transaction. Repositories called with the
+// context passed to fn take part in the transaction, and a nested call joins
+// the transaction that is already running.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
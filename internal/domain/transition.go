@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// StepEnd is the transition target that completes the workflow.
+const StepEnd Step = "end"
+
+// Transition is a guarded edge out of a step. The transitions of a step are
+// evaluated in order and the first one whose conditions all hold is taken;
+// when none matches the workflow falls through to the next step in the list.
+type Transition struct {
+	Name string      `json:"name" yaml:"name"`
+	When []Condition `json:"when,omitempty" yaml:"when,omitempty"`
+	Goto Step        `json:"goto" yaml:"goto"`
+}
+
+// Condition compares a field of the order data ("order.<key>") or of a
+// previous step's output ("steps.<step>.<key>") with Value.
+type Condition struct {
+	Field string `json:"field" yaml:"field"`
+	Op    string `json:"op" yaml:"op"` // eq, ne, gt, gte, lt, lte, in, exists, not_exists
+	Value any    `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// TransitionInput is the data conditions are evaluated against.
+type TransitionInput struct {
+	Order   map[string]any
+	Outputs map[Step]map[string]any
+}
+
+// BranchDecision records which transition was taken out of a step.
+type BranchDecision struct {
+	Step       Step      `json:"step"`
+	Transition string    `json:"transition"`
+	Goto       Step      `json:"goto"`
+	DecidedAt  time.Time `json:"decided_at"`
+}
+
+func (t Transition) Matches(in TransitionInput) (bool, error) {
+	for _, c := range t.When {
+		ok, err := c.Holds(in)
+		if err != nil {
+			return false, fmt.Errorf("transition %s: %w", t.Name, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (c Condition) Holds(in TransitionInput) (bool, error) {
+	actual, found, err := lookupField(c.Field, in)
+	if err != nil {
+		return false, err
+	}
+	switch c.Op {
+	case "exists":
+		return found, nil
+	case "not_exists":
+		return !found, nil
+	}
+	if !found {
+		return false, nil
+	}
+
+	switch c.Op {
+	case "eq":
+		return equalValues(actual, c.Value), nil
+	case "ne":
+		return !equalValues(actual, c.Value), nil
+	case "in":
+		list := reflect.ValueOf(c.Value)
+		if list.Kind() != reflect.Slice {
+			return false, fmt.Errorf("condition on %s: value of op in must be a list", c.Field)
+		}
+		for i := 0; i < list.Len(); i++ {
+			if equalValues(actual, list.Index(i).Interface()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "gt", "gte", "lt", "lte":
+		a, ok := toFloat(actual)
+		if !ok {
+			return false, nil
+		}
+		b, ok := toFloat(c.Value)
+		if !ok {
+			return false, fmt.Errorf("condition on %s: value %v is not a number", c.Field, c.Value)
+		}
+		switch c.Op {
+		case "gt":
+			return a > b, nil
+		case "gte":
+			return a >= b, nil
+		case "lt":
+			return a < b, nil
+		default:
+			return a <= b, nil
+		}
+	default:
+		return false, fmt.Errorf("condition on %s: unknown op %q", c.Field, c.Op)
+	}
+}
+
+func (c Condition) validate() error {
+	switch c.Op {
+	case "eq", "ne", "in", "gt", "gte", "lt", "lte", "exists", "not_exists":
+	default:
+		return fmt.Errorf("unknown op %q", c.Op)
+	}
+	root, _, _ := strings.Cut(c.Field, ".")
+	if root != "order" && root != "steps" {
+		return fmt.Errorf("field %q must start with order. or steps.", c.Field)
+	}
+	return nil
+}
+
+func lookupField(field string, in TransitionInput) (any, bool, error) {
+	parts := strings.Split(field, ".")
+	var cur any
+	switch {
+	case parts[0] == "order" && len(parts) >= 2:
+		cur = in.Order
+		parts = parts[1:]
+	case parts[0] == "steps" && len(parts) >= 3:
+		out, ok := in.Outputs[Step(parts[1])]
+		if !ok {
+			return nil, false, nil
+		}
+		cur = out
+		parts = parts[2:]
+	default:
+		return nil, false, fmt.Errorf("invalid condition field %q", field)
+	}
+
+	for _, p := range parts {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false, nil
+		}
+		if cur, ok = m[p]; !ok {
+			return nil, false, nil
+		}
+	}
+	return cur, true, nil
+}
+
+func equalValues(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
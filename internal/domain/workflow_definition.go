@@ -0,0 +1,278 @@
+package domain
+
+import "fmt"
+
+// StepDefinition describes a forward step and the compensation that undoes it.
+// A step with Parallel branches is a group: every branch is started at once and
+// the workflow only moves past the group when all of them have succeeded.
+// A step with a Workflow starts that workflow as a child and succeeds when the
+// child completes; compensating it compensates the child. A step with a Timer
+// succeeds once the timer fires, and a step with a Signal once the signal is
+// received. Retry only applies to steps run by an executor.
+type StepDefinition struct {
+	Name         Step              `json:"name" yaml:"name"`
+	Compensation CompensationStep  `json:"compensation,omitempty" yaml:"compensation,omitempty"`
+	Parallel     []StepDefinition  `json:"parallel,omitempty" yaml:"parallel,omitempty"`
+	Workflow     string            `json:"workflow,omitempty" yaml:"workflow,omitempty"`
+	Timer        *TimerDefinition  `json:"timer,omitempty" yaml:"timer,omitempty"`
+	Signal       *SignalDefinition `json:"signal,omitempty" yaml:"signal,omitempty"`
+	Retry        *RetryPolicy      `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Transitions  []Transition      `json:"transitions,omitempty" yaml:"transitions,omitempty"`
+}
+
+func (s StepDefinition) IsGroup() bool {
+	return len(s.Parallel) > 0
+}
+
+func (s StepDefinition) IsChild() bool {
+	return s.Workflow != ""
+}
+
+func (s StepDefinition) IsTimer() bool {
+	return s.Timer != nil
+}
+
+func (s StepDefinition) IsSignal() bool {
+	return s.Signal != nil
+}
+
+// IsEngineStep reports whether the engine performs s itself instead of a
+// registered executor.
+func (s StepDefinition) IsEngineStep() bool {
+	return s.IsChild() || s.IsTimer() || s.IsSignal()
+}
+
+// kinds counts how many of parallel, child workflow, timer and signal s is; a
+// step can be at most one of them.
+func (s StepDefinition) kinds() int {
+	n := 0
+	for _, is := range []bool{s.IsGroup(), s.IsChild(), s.IsTimer(), s.IsSignal()} {
+		if is {
+			n++
+		}
+	}
+	return n
+}
+
+// WorkflowDefinition is the ordered list of steps the engine runs for an order.
+// A workflow keeps running the Version it was started with, so any change to
+// the steps of a definition must be registered under a new version.
+type WorkflowDefinition struct {
+	Name    string           `json:"name" yaml:"name"`
+	Version int              `json:"version" yaml:"version"`
+	Steps   []StepDefinition `json:"steps" yaml:"steps"`
+}
+
+func DefaultWorkflowDefinition() *WorkflowDefinition {
+	return &WorkflowDefinition{
+		Name:    "order_fulfillment",
+		Version: 1,
+		Steps: []StepDefinition{
+			{Name: StepReserveSlot, Compensation: CompReleaseSlot},
+			{Name: StepAssignAgent, Compensation: CompUnassignAgent},
+			{Name: StepNotifyCustomer, Compensation: CompCancelNotification},
+		},
+	}
+}
+
+func (d *WorkflowDefinition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("workflow definition has no name")
+	}
+	if d.Version < 1 {
+		return fmt.Errorf("workflow %s: version must be at least 1", d.Name)
+	}
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("workflow %s has no steps", d.Name)
+	}
+	seen := make(map[Step]bool)
+	check := func(s StepDefinition, where string) error {
+		if s.Name == "" {
+			return fmt.Errorf("workflow %s: %s has no name", d.Name, where)
+		}
+		if s.Name == StepEnd {
+			return fmt.Errorf("workflow %s: %s is a reserved step name", d.Name, StepEnd)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("workflow %s: duplicate step %s", d.Name, s.Name)
+		}
+		seen[s.Name] = true
+		if s.kinds() > 1 {
+			return fmt.Errorf("workflow %s: step %s can only be one of a parallel group, a child workflow, a timer or a signal", d.Name, s.Name)
+		}
+		if s.IsEngineStep() && s.Compensation != "" {
+			return fmt.Errorf("workflow %s: step %s cannot have a compensation", d.Name, s.Name)
+		}
+		if s.IsTimer() {
+			if err := s.Timer.validate(); err != nil {
+				return fmt.Errorf("workflow %s, step %s: %w", d.Name, s.Name, err)
+			}
+		}
+		if s.IsSignal() {
+			if err := s.Signal.validate(); err != nil {
+				return fmt.Errorf("workflow %s, step %s: %w", d.Name, s.Name, err)
+			}
+		}
+		if s.Retry != nil {
+			if s.IsGroup() || s.IsEngineStep() {
+				return fmt.Errorf("workflow %s: step %s cannot have a retry policy", d.Name, s.Name)
+			}
+			if err := s.Retry.validate(); err != nil {
+				return fmt.Errorf("workflow %s, step %s: %w", d.Name, s.Name, err)
+			}
+		}
+		return nil
+	}
+	for i, s := range d.Steps {
+		if err := check(s, fmt.Sprintf("step %d", i)); err != nil {
+			return err
+		}
+		if err := d.validateTransitions(i); err != nil {
+			return err
+		}
+		if !s.IsGroup() {
+			continue
+		}
+		if s.Compensation != "" {
+			return fmt.Errorf("workflow %s: parallel group %s cannot have a compensation, set it on its branches", d.Name, s.Name)
+		}
+		for j, b := range s.Parallel {
+			if err := check(b, fmt.Sprintf("branch %d of %s", j, s.Name)); err != nil {
+				return err
+			}
+			if b.IsGroup() {
+				return fmt.Errorf("workflow %s: nested parallel group %s is not supported", d.Name, b.Name)
+			}
+			if len(b.Transitions) > 0 {
+				return fmt.Errorf("workflow %s: branch %s cannot have transitions, set them on group %s", d.Name, b.Name, s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTransitions checks that every transition out of the i-th step has a
+// valid condition and jumps forward, so a step never runs twice for an order.
+func (d *WorkflowDefinition) validateTransitions(i int) error {
+	s := d.Steps[i]
+	for _, t := range s.Transitions {
+		if t.Name == "" {
+			return fmt.Errorf("workflow %s: transition out of %s has no name", d.Name, s.Name)
+		}
+		for _, c := range t.When {
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("workflow %s: transition %s: %w", d.Name, t.Name, err)
+			}
+		}
+		if t.Goto == StepEnd {
+			continue
+		}
+		if j := d.indexOf(t.Goto); j <= i {
+			return fmt.Errorf("workflow %s: transition %s must go to a later top-level step or %s, got %q", d.Name, t.Name, StepEnd, t.Goto)
+		}
+	}
+	return nil
+}
+
+func (d *WorkflowDefinition) FirstStep() Step {
+	return d.Steps[0].Name
+}
+
+// Step looks up a step or parallel branch by name.
+func (d *WorkflowDefinition) Step(name Step) (StepDefinition, bool) {
+	for _, s := range d.Steps {
+		if s.Name == name {
+			return s, true
+		}
+		for _, b := range s.Parallel {
+			if b.Name == name {
+				return b, true
+			}
+		}
+	}
+	return StepDefinition{}, false
+}
+
+// Runnable returns the steps to enqueue when the workflow enters step: the
+// branches of a parallel group, or the step itself.
+func (d *WorkflowDefinition) Runnable(step Step) ([]Step, error) {
+	s, ok := d.Step(step)
+	if !ok {
+		return nil, fmt.Errorf("unknown step %s in workflow %s", step, d.Name)
+	}
+	if !s.IsGroup() {
+		return []Step{s.Name}, nil
+	}
+	steps := make([]Step, 0, len(s.Parallel))
+	for _, b := range s.Parallel {
+		steps = append(steps, b.Name)
+	}
+	return steps, nil
+}
+
+// ExecutableSteps returns every step that is run by an executor, which is
+// every step except parallel groups and engine steps.
+func (d *WorkflowDefinition) ExecutableSteps() []StepDefinition {
+	var steps []StepDefinition
+	for _, s := range d.leafSteps() {
+		if !s.IsEngineStep() {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// ChildSteps returns every step that starts a child workflow.
+func (d *WorkflowDefinition) ChildSteps() []StepDefinition {
+	var steps []StepDefinition
+	for _, s := range d.leafSteps() {
+		if s.IsChild() {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+func (d *WorkflowDefinition) leafSteps() []StepDefinition {
+	var steps []StepDefinition
+	for _, s := range d.Steps {
+		if s.IsGroup() {
+			steps = append(steps, s.Parallel...)
+			continue
+		}
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+// NextStep decides where the workflow goes once current has succeeded. It
+// returns StepEnd when the workflow is complete, and the name of the guarded
+// transition that was taken, or "" when it fell through to the next step.
+func (d *WorkflowDefinition) NextStep(current Step, in TransitionInput) (next Step, transition string, err error) {
+	i := d.indexOf(current)
+	if i < 0 {
+		return "", "", fmt.Errorf("unknown step %s in workflow %s", current, d.Name)
+	}
+	for _, t := range d.Steps[i].Transitions {
+		ok, err := t.Matches(in)
+		if err != nil {
+			return "", "", fmt.Errorf("workflow %s: %w", d.Name, err)
+		}
+		if ok {
+			return t.Goto, t.Name, nil
+		}
+	}
+	if i == len(d.Steps)-1 {
+		return StepEnd, "", nil
+	}
+	return d.Steps[i+1].Name, "", nil
+}
+
+func (d *WorkflowDefinition) indexOf(step Step) int {
+	for i, s := range d.Steps {
+		if s.Name == step {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,334 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventWorkflowStarted       EventType = "WorkflowStarted"
+	EventStepStarted           EventType = "StepStarted"   // the workflow entered Step, running Steps
+	EventStepSucceeded         EventType = "StepSucceeded" // Step succeeded with Output
+	EventStepFailed            EventType = "StepFailed"
+	EventStepsRewound          EventType = "StepsRewound" // Steps run again, see rewind
+	EventTransitionTaken       EventType = "TransitionTaken"
+	EventTimerSet              EventType = "TimerSet"
+	EventCompensationStarted   EventType = "CompensationStarted"   // the rollback started with Steps to compensate, for a retry from Step if set
+	EventCompensationScheduled EventType = "CompensationScheduled" // Step is added to the end of the rollback
+	EventCompensationSucceeded EventType = "CompensationSucceeded"
+	EventCompensationFailed    EventType = "CompensationFailed"
+	EventCompensationRetried   EventType = "CompensationRetried"
+	EventWorkflowPaused        EventType = "WorkflowPaused" // paused by PausedBy, holding Steps
+	EventStepsHeld             EventType = "StepsHeld"      // Steps are not run until the workflow resumes
+	EventStatusChanged         EventType = "StatusChanged"
+)
+
+// WorkflowEvent is one change to a workflow in its event stream. The state of
+// a workflow is the fold of its events, see FoldEvents. Which of the other
+// fields are set depends on Type.
+type WorkflowEvent struct {
+	OrderID   string    `json:"-"`
+	Sequence  int64     `json:"-"` // position in the stream of the order, from 1
+	Type      EventType `json:"-"`
+	CreatedAt time.Time `json:"-"`
+
+	Workflow        string               `json:"workflow,omitempty"`
+	WorkflowVersion int                  `json:"workflow_version,omitempty"`
+	RootOrderID     string               `json:"root_order_id,omitempty"`
+	ParentOrderID   string               `json:"parent_order_id,omitempty"`
+	ParentStep      Step                 `json:"parent_step,omitempty"`
+	Step            Step                 `json:"step,omitempty"`
+	Steps           []Step               `json:"steps,omitempty"`
+	Output          map[string]any       `json:"output,omitempty"`
+	Branch          *BranchDecision      `json:"branch,omitempty"`
+	WakeAt          time.Time            `json:"wake_at,omitzero"`
+	Failure         *CompensationFailure `json:"failure,omitempty"`
+	Status          WorkflowStatus       `json:"status,omitempty"`
+	PausedBy        PauseOrigin          `json:"paused_by,omitempty"`
+}
+
+// FoldEvents applies events, in order, to state, which is nil for a stream
+// that starts with WorkflowStarted. state itself is left unchanged.
+func FoldEvents(state *WorkflowState, events []*WorkflowEvent) (*WorkflowState, error) {
+	if state != nil {
+		state = state.Clone()
+	}
+	for _, e := range events {
+		if state == nil && e.Type != EventWorkflowStarted {
+			return nil, fmt.Errorf("event %d of order %s: stream does not start with %s", e.Sequence, e.OrderID, EventWorkflowStarted)
+		}
+		if state != nil && e.Sequence != state.Version+1 {
+			return nil, fmt.Errorf("event %d of order %s: expected event %d", e.Sequence, e.OrderID, state.Version+1)
+		}
+		var err error
+		if state, err = e.apply(state); err != nil {
+			return nil, fmt.Errorf("event %d of order %s: %w", e.Sequence, e.OrderID, err)
+		}
+		if state.Status != StatusPaused {
+			state.PausedBy = ""
+			state.HeldSteps = nil
+		}
+		if state.Status != StatusCompensating && state.Status != StatusCompensationFailed {
+			state.RetryFrom = ""
+		}
+		state.Version = e.Sequence
+		state.UpdatedAt = e.CreatedAt
+	}
+	return state, nil
+}
+
+func (e *WorkflowEvent) apply(state *WorkflowState) (*WorkflowState, error) {
+	switch e.Type {
+	case EventWorkflowStarted:
+		if state != nil {
+			return nil, fmt.Errorf("workflow already started")
+		}
+		root := e.RootOrderID
+		if root == "" {
+			// streams started before the order was recorded apart
+			root, _, _ = strings.Cut(e.OrderID, "/")
+		}
+		return &WorkflowState{
+			OrderID:         e.OrderID,
+			RootOrderID:     root,
+			Workflow:        e.Workflow,
+			WorkflowVersion: e.WorkflowVersion,
+			ParentOrderID:   e.ParentOrderID,
+			ParentStep:      e.ParentStep,
+			CurrentStep:     e.Step,
+			ActiveSteps:     slices.Clone(e.Steps),
+			Status:          e.Status,
+			CreatedAt:       e.CreatedAt,
+		}, nil
+	case EventStepStarted:
+		state.CurrentStep = e.Step
+		state.ActiveSteps = slices.Clone(e.Steps)
+	case EventStepSucceeded:
+		if !slices.Contains(state.ActiveSteps, e.Step) {
+			return nil, fmt.Errorf("step %s is not active", e.Step)
+		}
+		state.ActiveSteps = slices.DeleteFunc(state.ActiveSteps, func(s Step) bool { return s == e.Step })
+		state.CompletedSteps = append(state.CompletedSteps, e.Step)
+		if state.StepOutputs == nil {
+			state.StepOutputs = make(map[Step]map[string]any)
+		}
+		output := e.Output
+		if output == nil {
+			output = map[string]any{}
+		}
+		state.StepOutputs[e.Step] = output
+	case EventStepFailed:
+		state.ActiveSteps = slices.DeleteFunc(state.ActiveSteps, func(s Step) bool { return s == e.Step })
+	case EventStepsRewound:
+		state.rewind(e.Steps)
+	case EventTransitionTaken:
+		if e.Branch == nil {
+			return nil, fmt.Errorf("%s without a branch", e.Type)
+		}
+		state.Branches = append(state.Branches, *e.Branch)
+	case EventTimerSet:
+		if state.Timers == nil {
+			state.Timers = make(map[Step]time.Time)
+		}
+		state.Timers[e.Step] = e.WakeAt
+	case EventCompensationStarted:
+		state.Status = StatusCompensating
+		state.PendingCompensations = slices.Clone(e.Steps)
+		state.RetryFrom = e.Step
+	case EventCompensationScheduled:
+		state.PendingCompensations = append(state.PendingCompensations, e.Step)
+	case EventCompensationSucceeded:
+		if len(state.PendingCompensations) == 0 || state.PendingCompensations[0] != e.Step {
+			return nil, fmt.Errorf("step %s is not the next to compensate", e.Step)
+		}
+		state.PendingCompensations = state.PendingCompensations[1:]
+	case EventCompensationFailed:
+		state.Status = StatusCompensationFailed
+		state.CompensationFailure = e.Failure
+	case EventCompensationRetried:
+		state.Status = StatusCompensating
+		state.CompensationFailure = nil
+	case EventWorkflowPaused:
+		state.Status = StatusPaused
+		state.PausedBy = e.PausedBy
+		state.HeldSteps = slices.Clone(e.Steps)
+	case EventStepsHeld:
+		state.HeldSteps = append(state.HeldSteps, e.Steps...)
+	case EventStatusChanged:
+		if e.Status == StatusPaused && state.Status != StatusPaused {
+			// streams paused before what paused them was recorded; resuming
+			// them enqueues every active step
+			state.HeldSteps = slices.Clone(state.ActiveSteps)
+		}
+		state.Status = e.Status
+	default:
+		return nil, fmt.Errorf("unknown event type %q", e.Type)
+	}
+	return state, nil
+}
+
+// rewind undoes steps, so that they run again: the completed steps are cut
+// back to before the first of them, and their outputs and timers dropped.
+func (s *WorkflowState) rewind(steps []Step) {
+	if i := slices.IndexFunc(s.CompletedSteps, func(step Step) bool { return slices.Contains(steps, step) }); i >= 0 {
+		s.CompletedSteps = s.CompletedSteps[:i]
+	}
+	for _, step := range steps {
+		delete(s.StepOutputs, step)
+		delete(s.Timers, step)
+	}
+}
+
+// StateEvents returns the events that turn current into state, the state a
+// usecase saves. current is nil for a new workflow. It fails if the change
+// cannot be expressed as events.
+func StateEvents(current, state *WorkflowState) ([]*WorkflowEvent, error) {
+	if current == nil {
+		events := []*WorkflowEvent{{
+			Type:            EventWorkflowStarted,
+			Workflow:        state.Workflow,
+			WorkflowVersion: state.WorkflowVersion,
+			RootOrderID:     state.RootOrderID,
+			ParentOrderID:   state.ParentOrderID,
+			ParentStep:      state.ParentStep,
+			Step:            state.CurrentStep,
+			Steps:           state.ActiveSteps,
+			Status:          state.Status,
+			CreatedAt:       state.CreatedAt,
+		}}
+		return events, checkEvents(nil, state, events)
+	}
+
+	var events []*WorkflowEvent
+	if rewound := rewoundSteps(current, state); len(rewound) > 0 {
+		events = append(events, &WorkflowEvent{Type: EventStepsRewound, Steps: rewound})
+	}
+	active := current.ActiveSteps
+	for _, step := range current.ActiveSteps {
+		if !slices.Contains(state.ActiveSteps, step) && current.CurrentStep == state.CurrentStep {
+			events = append(events, &WorkflowEvent{Type: EventStepFailed, Step: step})
+			active = slices.DeleteFunc(slices.Clone(active), func(s Step) bool { return s == step })
+		}
+	}
+	for i := len(current.Branches); i < len(state.Branches); i++ {
+		events = append(events, &WorkflowEvent{Type: EventTransitionTaken, Branch: &state.Branches[i]})
+	}
+	if state.CurrentStep != current.CurrentStep || !slices.Equal(state.ActiveSteps, active) {
+		events = append(events, &WorkflowEvent{Type: EventStepStarted, Step: state.CurrentStep, Steps: state.ActiveSteps})
+	}
+
+	status := current.Status
+	if state.Status == StatusCompensating && !current.Status.IsRollback() {
+		events = append(events, &WorkflowEvent{Type: EventCompensationStarted, Steps: state.PendingCompensations, Step: state.RetryFrom})
+		status = StatusCompensating
+	}
+	switch {
+	case state.CompensationFailure != nil && !sameFailure(current.CompensationFailure, state.CompensationFailure):
+		events = append(events, &WorkflowEvent{Type: EventCompensationFailed, Failure: state.CompensationFailure})
+		status = StatusCompensationFailed
+	case state.CompensationFailure == nil && current.CompensationFailure != nil:
+		events = append(events, &WorkflowEvent{Type: EventCompensationRetried})
+		status = StatusCompensating
+	}
+	switch {
+	case state.Status == StatusPaused && status != StatusPaused:
+		events = append(events, &WorkflowEvent{Type: EventWorkflowPaused, PausedBy: state.PausedBy, Steps: state.HeldSteps})
+		status = StatusPaused
+	case state.Status == StatusPaused && len(state.HeldSteps) > len(current.HeldSteps):
+		events = append(events, &WorkflowEvent{Type: EventStepsHeld, Steps: state.HeldSteps[len(current.HeldSteps):]})
+	}
+	if state.Status != status {
+		events = append(events, &WorkflowEvent{Type: EventStatusChanged, Status: state.Status})
+	}
+	return events, checkEvents(current, state, events)
+}
+
+// rewoundSteps returns the steps state runs again: those no longer completed,
+// and those whose output or timer it dropped.
+func rewoundSteps(current, state *WorkflowState) []Step {
+	var steps []Step
+	add := func(step Step) {
+		if !slices.Contains(steps, step) {
+			steps = append(steps, step)
+		}
+	}
+	if n := len(state.CompletedSteps); n < len(current.CompletedSteps) && slices.Equal(state.CompletedSteps, current.CompletedSteps[:n]) {
+		for _, step := range current.CompletedSteps[n:] {
+			add(step)
+		}
+	}
+	for _, step := range slices.Sorted(maps.Keys(current.StepOutputs)) {
+		if _, ok := state.StepOutputs[step]; !ok {
+			add(step)
+		}
+	}
+	for _, step := range slices.Sorted(maps.Keys(current.Timers)) {
+		if _, ok := state.Timers[step]; !ok {
+			add(step)
+		}
+	}
+	return steps
+}
+
+// checkEvents verifies that folding events into current gives state.
+func checkEvents(current, state *WorkflowState, events []*WorkflowEvent) error {
+	var version int64
+	if current != nil {
+		version = current.Version
+	}
+	for i, e := range events {
+		e.OrderID = state.OrderID
+		e.Sequence = version + int64(i) + 1
+	}
+	folded, err := FoldEvents(current, events)
+	if err != nil {
+		return err
+	}
+	same := folded.CurrentStep == state.CurrentStep &&
+		slices.Equal(folded.ActiveSteps, state.ActiveSteps) &&
+		slices.Equal(folded.CompletedSteps, state.CompletedSteps) &&
+		slices.Equal(folded.PendingCompensations, state.PendingCompensations) &&
+		len(folded.StepOutputs) == len(state.StepOutputs) &&
+		slices.EqualFunc(folded.Branches, state.Branches, func(a, b BranchDecision) bool {
+			return a.Step == b.Step && a.Transition == b.Transition && a.Goto == b.Goto && a.DecidedAt.Equal(b.DecidedAt)
+		}) &&
+		maps.EqualFunc(folded.Timers, state.Timers, time.Time.Equal) &&
+		sameFailure(folded.CompensationFailure, state.CompensationFailure) &&
+		folded.Status == state.Status &&
+		folded.PausedBy == state.PausedBy &&
+		slices.Equal(folded.HeldSteps, state.HeldSteps) &&
+		folded.RetryFrom == state.RetryFrom
+	if !same {
+		return fmt.Errorf("change to workflow %s cannot be stored as events", state.OrderID)
+	}
+	return nil
+}
+
+func sameFailure(a, b *CompensationFailure) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Step == b.Step && a.Compensation == b.Compensation && a.Error == b.Error && a.FailedAt.Equal(b.FailedAt)
+}
+
+// Clone returns a copy of s that can be changed without changing s.
+func (s *WorkflowState) Clone() *WorkflowState {
+	c := *s
+	c.ActiveSteps = slices.Clone(s.ActiveSteps)
+	c.CompletedSteps = slices.Clone(s.CompletedSteps)
+	c.PendingCompensations = slices.Clone(s.PendingCompensations)
+	c.StepOutputs = maps.Clone(s.StepOutputs)
+	c.Branches = slices.Clone(s.Branches)
+	c.Timers = maps.Clone(s.Timers)
+	c.HeldSteps = slices.Clone(s.HeldSteps)
+	if s.CompensationFailure != nil {
+		failure := *s.CompensationFailure
+		c.CompensationFailure = &failure
+	}
+	return &c
+}
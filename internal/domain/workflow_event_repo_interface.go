@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+type WorkflowEventRepo interface {
+	// AppendEvents adds events to the stream of orderID, numbering them from
+	// version+1. It fails with a VersionConflictError if the stream is no
+	// longer at version.
+	AppendEvents(ctx context.Context, orderID string, version int64, events []*WorkflowEvent) error
+	// GetEvents returns the events of orderID after sequence, oldest first.
+	GetEvents(ctx context.Context, orderID string, after int64) ([]*WorkflowEvent, error)
+	GetOrderIDs(ctx context.Context) ([]string, error)
+	// GetSnapshot returns the latest snapshot of orderID, or nil if it has
+	// none. Its Version is the sequence of the last event folded into it.
+	GetSnapshot(ctx context.Context, orderID string) (*WorkflowState, error)
+	SaveSnapshot(ctx context.Context, state *WorkflowState) error
+}
+
+// EventSourcedWorkflowRepo stores workflows as event streams and keeps the
+// workflows table as a projection of them.
+type EventSourcedWorkflowRepo interface {
+	WorkflowRepo
+	// RebuildProjection folds the whole event stream of orderID, ignoring
+	// its snapshot, and rewrites the snapshot and the workflows row from it.
+	RebuildProjection(ctx context.Context, orderID string) (*WorkflowState, error)
+}
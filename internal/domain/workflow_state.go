@@ -1,22 +1,50 @@
 package domain
 
-import "time"
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
 
 type WorkflowStatus string
 
 const (
-	StatusPending     WorkflowStatus = "pending"
-	StatusCompleted   WorkflowStatus = "completed"
-	StatusFailed      WorkflowStatus = "failed"
-	StatusCompensated WorkflowStatus = "compensated"
+	StatusPending            WorkflowStatus = "pending"
+	StatusCompleted          WorkflowStatus = "completed"
+	StatusFailed             WorkflowStatus = "failed"
+	StatusCompensated        WorkflowStatus = "compensated"
+	StatusWaiting            WorkflowStatus = "waiting"             // parked on a signal step
+	StatusCompensating       WorkflowStatus = "compensating"        // rollback in progress
+	StatusCompensationFailed WorkflowStatus = "compensation_failed" // rollback parked until an operator retries it
+	StatusCancelled          WorkflowStatus = "cancelled"           // cancelled on request and rolled back
+	StatusPaused             WorkflowStatus = "paused"              // held by an operator until resumed
 )
 
+// IsActive reports whether a workflow in this status can still move forward.
+func (s WorkflowStatus) IsActive() bool {
+	return s == StatusPending || s == StatusWaiting
+}
+
+// IsFinal reports whether a workflow in this status is done and will not
+// change again.
+func (s WorkflowStatus) IsFinal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCompensated || s == StatusCancelled
+}
+
+// IsRollback reports whether a workflow in this status has started rolling
+// back, whether or not the rollback is done.
+func (s WorkflowStatus) IsRollback() bool {
+	return s == StatusCompensating || s == StatusCompensated || s == StatusCompensationFailed || s == StatusCancelled
+}
+
 type Step string
 
 const (
 	StepReserveSlot    Step = "reserve_pickup_slot"
 	StepAssignAgent    Step = "assign_agent"
 	StepNotifyCustomer Step = "notify_customer"
+	StepReserveStation Step = "reserve_packaging_station"
 )
 
 type CompensationStep string
@@ -25,12 +53,93 @@ const (
 	CompReleaseSlot        CompensationStep = "release_pickup_slot"
 	CompUnassignAgent      CompensationStep = "unassign_agent"
 	CompCancelNotification CompensationStep = "cancel_notification"
+	CompReleaseStation     CompensationStep = "release_packaging_station"
 )
 
 type WorkflowState struct {
-	OrderID     string
-	CurrentStep Step
-	Status      WorkflowStatus
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	OrderID              string // key of the workflow: its order, or ChildWorkflowID for a child workflow
+	RootOrderID          string // order the workflow runs for; differs from OrderID on child workflows
+	Workflow             string // name of the definition this workflow runs
+	WorkflowVersion      int    // version of the definition, pinned when the workflow started
+	ParentOrderID        string // set on child workflows, together with ParentStep
+	ParentStep           Step
+	CurrentStep          Step
+	ActiveSteps          []Step // steps enqueued and not yet succeeded; several while a parallel group runs
+	CompletedSteps       []Step // steps that succeeded, in completion order
+	PendingCompensations []Step // completed steps still to undo in a rollback, most recent first
+	StepOutputs          map[Step]map[string]any
+	Branches             []BranchDecision     // guarded transitions taken so far, for auditing
+	Timers               map[Step]time.Time   // wake-up time of each timer step that has started
+	CompensationFailure  *CompensationFailure // set while the rollback is parked
+	PausedBy             PauseOrigin          // set while the workflow is paused
+	HeldSteps            []Step               // active steps not run while paused; resuming enqueues them
+	RetryFrom            Step                 // set while a retry from it compensates the steps it runs again
+	Status               WorkflowStatus
+	Version              int64 // bumped on every change; SaveState fails with a VersionConflictError on a stale one
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// CompensationFailure records the compensation a rollback gave up on. For a
+// child workflow step, Error describes the failure inside the child.
+type CompensationFailure struct {
+	Step         Step             `json:"step"`
+	Compensation CompensationStep `json:"compensation,omitempty"`
+	Error        string           `json:"error"`
+	FailedAt     time.Time        `json:"failed_at"`
+}
+
+// WorkflowVersionUsage is the number of unfinished workflows pinned to a
+// definition version. A version with no running workflows can be retired.
+type WorkflowVersionUsage struct {
+	Workflow string
+	Version  int
+	Running  int
+}
+
+// WorkflowFilter selects the workflows ListWorkflows returns, newest first.
+// Zero fields select every workflow.
+type WorkflowFilter struct {
+	Statuses      []WorkflowStatus // any of them
+	CreatedAfter  time.Time        // inclusive
+	CreatedBefore time.Time        // exclusive
+	After         *WorkflowCursor  // continue the list after this workflow
+	Limit         int
+}
+
+// WorkflowCursor is the position of a workflow in the list order, which is by
+// creation time and then order ID, both descending.
+type WorkflowCursor struct {
+	CreatedAt time.Time
+	OrderID   string
+}
+
+// Encode returns c as an opaque page token.
+func (c WorkflowCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.Format(time.RFC3339Nano) + "|" + c.OrderID))
+}
+
+// DecodeWorkflowCursor parses a page token made by Encode.
+func DecodeWorkflowCursor(token string) (*WorkflowCursor, error) {
+	invalid := errors.New("invalid page token")
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAt, orderID, ok := strings.Cut(string(data), "|")
+	if !ok {
+		return nil, invalid
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, invalid
+	}
+	return &WorkflowCursor{CreatedAt: t, OrderID: orderID}, nil
+}
+
+// ChildWorkflowID is the key under which step of the workflow parentOrderID
+// runs its child workflow. It is not an order ID: the child runs for the order
+// of its parent, its RootOrderID.
+func ChildWorkflowID(parentOrderID string, step Step) string {
+	return parentOrderID + "/" + string(step)
 }
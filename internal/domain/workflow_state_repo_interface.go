@@ -9,4 +9,24 @@ type WorkflowRepo interface {
 	SaveState(ctx context.Context, state *WorkflowState) error
 	GetStateByOrderID(ctx context.Context, orderID string) (*WorkflowState, error)
 	GetStalledWorkflows(ctx context.Context, timeout time.Duration) ([]*WorkflowState, error)
-}
\ No newline at end of file
+	// CompleteStep atomically moves step from the active to the completed
+	// steps, records its output and returns the resulting state, or nil if
+	// step was not active.
+	CompleteStep(ctx context.Context, orderID string, step Step, output map[string]any) (*WorkflowState, error)
+	// AppendCompensation adds step to the end of the pending compensations
+	// and returns the resulting state.
+	AppendCompensation(ctx context.Context, orderID string, step Step) (*WorkflowState, error)
+	// PopCompensation removes step from the front of the pending
+	// compensations and returns the resulting state, or nil if step was not
+	// the next one to compensate.
+	PopCompensation(ctx context.Context, orderID string, step Step) (*WorkflowState, error)
+	// UpdateStatus changes the status of a workflow only if it is still from,
+	// and reports whether it did.
+	UpdateStatus(ctx context.Context, orderID string, from, to WorkflowStatus) (bool, error)
+	// SetTimer records when the timer of step fires, unless it already has a
+	// wake-up time, and returns the stored one.
+	SetTimer(ctx context.Context, orderID string, step Step, wakeAt time.Time) (time.Time, error)
+	// GetRunningVersions counts unfinished workflows per definition version.
+	GetRunningVersions(ctx context.Context) ([]*WorkflowVersionUsage, error)
+	ListWorkflows(ctx context.Context, filter WorkflowFilter) ([]*WorkflowState, error)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// WorkflowTransition is one entry in the append-only history of a workflow.
+// During a rollback the step is the next one to compensate.
+type WorkflowTransition struct {
+	ID         int64
+	OrderID    string
+	FromStep   Step
+	ToStep     Step
+	FromStatus WorkflowStatus // empty for the transition that started the workflow
+	ToStatus   WorkflowStatus
+	Cause      string
+	Error      string
+	Worker     string // host and process that made the change
+	CreatedAt  time.Time
+}
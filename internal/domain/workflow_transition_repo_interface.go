@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+type WorkflowTransitionRepo interface {
+	AddTransition(ctx context.Context, t *WorkflowTransition) error
+	// GetTransitionsByOrderID returns the history of a workflow, oldest first.
+	GetTransitionsByOrderID(ctx context.Context, orderID string) ([]*WorkflowTransition, error)
+	// GetTransitionsAfter returns the transitions of a workflow made after the
+	// one with ID afterID, oldest first.
+	GetTransitionsAfter(ctx context.Context, orderID string, afterID int64) ([]*WorkflowTransition, error)
+}
@@ -23,7 +23,7 @@ func (r *postgresAgentRepo) AssignAgent(ctx context.Context, orderID, agentID st
         VALUES ($1, $2, $3)
         ON CONFLICT (order_id, agent_id) DO NOTHING
     `
-	_, err := r.db.ExecContext(ctx, query, orderID, agentID, time.Now())
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, orderID, agentID, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to assign agent %s to order %s: %w", agentID, orderID, err)
 	}
@@ -32,7 +32,7 @@ func (r *postgresAgentRepo) AssignAgent(ctx context.Context, orderID, agentID st
 
 func (r *postgresAgentRepo) GetAgentsByOrderID(ctx context.Context, orderID string) ([]string, error) {
 	query := `SELECT agent_id FROM agents WHERE order_id = $1`
-	rows, err := r.db.QueryContext(ctx, query, orderID)
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, orderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agents for order %s: %w", orderID, err)
 	}
@@ -51,7 +51,7 @@ func (r *postgresAgentRepo) GetAgentsByOrderID(ctx context.Context, orderID stri
 
 func (r *postgresAgentRepo) UnassignAgents(ctx context.Context, orderID string) error {
 	query := `DELETE FROM agents WHERE order_id = $1`
-	_, err := r.db.ExecContext(ctx, query, orderID)
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to unassign agents for order %s: %w", orderID, err)
 	}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+const compensationExecutionColumns = `dedupe_key, order_id, compensation, result, attempts, last_error, started_at, updated_at`
+
+type postgresCompensationExecutionRepo struct {
+	db *sql.DB
+}
+
+func NewCompensationExecutionRepo(db *sql.DB) domain.CompensationExecutionRepo {
+	return &postgresCompensationExecutionRepo{db: db}
+}
+
+func (r *postgresCompensationExecutionRepo) GetCompensationExecution(ctx context.Context, dedupeKey string) (*domain.CompensationExecution, error) {
+	query := `SELECT ` + compensationExecutionColumns + ` FROM compensation_executions WHERE dedupe_key = $1`
+	exec, err := scanCompensationExecution(dbFrom(ctx, r.db).QueryRowContext(ctx, query, dedupeKey))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compensation execution: %w", err)
+	}
+	return exec, nil
+}
+
+func (r *postgresCompensationExecutionRepo) ClaimCompensation(ctx context.Context, exec *domain.CompensationExecution) (bool, error) {
+	query := `
+		INSERT INTO compensation_executions (dedupe_key, order_id, compensation, result)
+		VALUES ($1, $2, $3, 'in_progress')
+		ON CONFLICT (dedupe_key) DO UPDATE SET
+			result = 'in_progress',
+			attempts = compensation_executions.attempts + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE compensation_executions.result <> 'success'
+		RETURNING attempts
+	`
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, exec.DedupeKey, exec.OrderID, exec.Compensation).Scan(&exec.Attempts)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim compensation execution: %w", err)
+	}
+	return true, nil
+}
+
+func (r *postgresCompensationExecutionRepo) SaveCompensationAttempt(ctx context.Context, exec *domain.CompensationExecution) error {
+	query := `
+		INSERT INTO compensation_executions (dedupe_key, order_id, compensation, result, last_error)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (dedupe_key) DO UPDATE SET
+			result = CASE WHEN compensation_executions.result = 'success' THEN 'success' ELSE EXCLUDED.result END,
+			last_error = COALESCE(EXCLUDED.last_error, compensation_executions.last_error),
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING attempts
+	`
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query,
+		exec.DedupeKey, exec.OrderID, exec.Compensation, exec.Result, nullString(exec.LastError)).Scan(&exec.Attempts)
+	if err != nil {
+		return fmt.Errorf("failed to save compensation execution: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresCompensationExecutionRepo) GetCompensationExecutionsByOrderID(ctx context.Context, orderID string) ([]*domain.CompensationExecution, error) {
+	query := `SELECT ` + compensationExecutionColumns + ` FROM compensation_executions WHERE order_id = $1 ORDER BY started_at, id`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compensation executions: %w", err)
+	}
+	defer rows.Close()
+
+	var execs []*domain.CompensationExecution
+	for rows.Next() {
+		exec, err := scanCompensationExecution(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan compensation execution: %w", err)
+		}
+		execs = append(execs, exec)
+	}
+	return execs, rows.Err()
+}
+
+func (r *postgresCompensationExecutionRepo) DeleteCompensationExecution(ctx context.Context, dedupeKey string) error {
+	query := `DELETE FROM compensation_executions WHERE dedupe_key = $1`
+	if _, err := dbFrom(ctx, r.db).ExecContext(ctx, query, dedupeKey); err != nil {
+		return fmt.Errorf("failed to delete compensation execution: %w", err)
+	}
+	return nil
+}
+
+func scanCompensationExecution(row rowScanner) (*domain.CompensationExecution, error) {
+	exec := &domain.CompensationExecution{}
+	var lastError sql.NullString
+	err := row.Scan(&exec.DedupeKey, &exec.OrderID, &exec.Compensation, &exec.Result, &exec.Attempts, &lastError, &exec.StartedAt, &exec.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	exec.LastError = lastError.String
+	return exec, nil
+}
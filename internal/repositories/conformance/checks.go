@@ -0,0 +1,806 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+func checkOrders(ctx context.Context, r Repos, orderID string) error {
+	order, err := r.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		return fmt.Errorf("got order %s before saving it", orderID)
+	}
+
+	now := time.Now()
+	order = &domain.Order{ID: orderID, Status: "pending", Data: map[string]any{"items": 2, "zone": "north"}, CreatedAt: now, UpdatedAt: now}
+	if err := r.Orders.SaveOrder(ctx, order); err != nil {
+		return err
+	}
+	// saving again only changes the status
+	if err := r.Orders.SaveOrder(ctx, &domain.Order{ID: orderID, Status: "fulfilled", Data: map[string]any{"zone": "south"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		return err
+	}
+	got, err := r.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if got == nil {
+		return fmt.Errorf("order %s not found after saving it", orderID)
+	}
+	if got.Status != "fulfilled" {
+		return fmt.Errorf("status is %q, want fulfilled", got.Status)
+	}
+	if got.Data["items"] != float64(2) || got.Data["zone"] != "north" {
+		return fmt.Errorf("data is %v, want the data it was first saved with, as decoded from JSON", got.Data)
+	}
+
+	got.Data["zone"] = "changed"
+	again, err := r.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if again.Data["zone"] != "north" {
+		return fmt.Errorf("changing a loaded order changed the stored one")
+	}
+	return nil
+}
+
+func checkAgents(ctx context.Context, r Repos, orderID string) error {
+	if err := saveOrder(ctx, r, orderID); err != nil {
+		return err
+	}
+	agents, err := r.Agents.GetAgentsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(agents) != 0 {
+		return fmt.Errorf("got agents %v before assigning any", agents)
+	}
+	for _, agent := range []string{"agent-1", "agent-2", "agent-1"} {
+		if err := r.Agents.AssignAgent(ctx, orderID, agent); err != nil {
+			return err
+		}
+	}
+	agents, err = r.Agents.GetAgentsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	slices.Sort(agents)
+	if !slices.Equal(agents, []string{"agent-1", "agent-2"}) {
+		return fmt.Errorf("got agents %v, want each assigned agent once", agents)
+	}
+	if err := r.Agents.UnassignAgents(ctx, orderID); err != nil {
+		return err
+	}
+	agents, err = r.Agents.GetAgentsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(agents) != 0 {
+		return fmt.Errorf("got agents %v after unassigning them", agents)
+	}
+	return nil
+}
+
+func checkWorkflows(ctx context.Context, r Repos, orderID string) error {
+	state, err := r.Workflows.GetStateByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		return fmt.Errorf("got workflow %s before saving it", orderID)
+	}
+	state, err = saveWorkflow(ctx, r, orderID, "conformance", 1, domain.StatusPending)
+	if err != nil {
+		return err
+	}
+	if state.Version != 1 {
+		return fmt.Errorf("new workflow is at version %d, want 1", state.Version)
+	}
+
+	stale := state.Clone()
+	state.CurrentStep = "second"
+	if err := r.Workflows.SaveState(ctx, state); err != nil {
+		return err
+	}
+	if state.Version != 2 {
+		return fmt.Errorf("saved workflow is at version %d, want 2", state.Version)
+	}
+	stale.Status = domain.StatusFailed
+	if err := r.Workflows.SaveState(ctx, stale); !errors.Is(err, domain.ErrVersionConflict) {
+		return fmt.Errorf("saving a stale workflow returned %v, want a version conflict", err)
+	}
+
+	got, err := r.Workflows.CompleteStep(ctx, orderID, "other", nil)
+	if err != nil {
+		return err
+	}
+	if got != nil {
+		return fmt.Errorf("completing a step that is not active returned a state")
+	}
+	got, err = r.Workflows.CompleteStep(ctx, orderID, "first", map[string]any{"slot": 7})
+	if err != nil {
+		return err
+	}
+	if got == nil {
+		return fmt.Errorf("completing an active step returned no state")
+	}
+	if !slices.Equal(got.ActiveSteps, []domain.Step{"second"}) || !slices.Equal(got.CompletedSteps, []domain.Step{"first"}) {
+		return fmt.Errorf("after completing first, active steps are %v and completed steps %v", got.ActiveSteps, got.CompletedSteps)
+	}
+	if got.StepOutputs["first"]["slot"] != float64(7) {
+		return fmt.Errorf("output of first is %v", got.StepOutputs["first"])
+	}
+	if got.CurrentStep != "second" || got.Version != 3 {
+		return fmt.Errorf("after completing first, current step is %s at version %d, want second at version 3", got.CurrentStep, got.Version)
+	}
+
+	for _, step := range []domain.Step{"first", "second"} {
+		if got, err = r.Workflows.AppendCompensation(ctx, orderID, step); err != nil {
+			return err
+		}
+	}
+	if !slices.Equal(got.PendingCompensations, []domain.Step{"first", "second"}) {
+		return fmt.Errorf("pending compensations are %v, want [first second]", got.PendingCompensations)
+	}
+	if got, err = r.Workflows.PopCompensation(ctx, orderID, "second"); err != nil || got != nil {
+		return fmt.Errorf("popping a compensation that is not next returned %v, %v", got, err)
+	}
+	if got, err = r.Workflows.PopCompensation(ctx, orderID, "first"); err != nil || got == nil {
+		return fmt.Errorf("popping the next compensation returned %v, %v", got, err)
+	}
+	if !slices.Equal(got.PendingCompensations, []domain.Step{"second"}) {
+		return fmt.Errorf("pending compensations are %v after popping first, want [second]", got.PendingCompensations)
+	}
+
+	if ok, err := r.Workflows.UpdateStatus(ctx, orderID, domain.StatusWaiting, domain.StatusFailed); err != nil || ok {
+		return fmt.Errorf("updating the status from one the workflow is not in returned %v, %v", ok, err)
+	}
+	if ok, err := r.Workflows.UpdateStatus(ctx, orderID, domain.StatusPending, domain.StatusCompensating); err != nil || !ok {
+		return fmt.Errorf("updating the status returned %v, %v", ok, err)
+	}
+
+	wakeAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	stored, err := r.Workflows.SetTimer(ctx, orderID, "wait", wakeAt)
+	if err != nil {
+		return err
+	}
+	if !stored.Equal(wakeAt) {
+		return fmt.Errorf("timer is set to %v, want %v", stored, wakeAt)
+	}
+	if state, err = r.Workflows.GetStateByOrderID(ctx, orderID); err != nil {
+		return err
+	}
+	if state.Version != 8 {
+		return fmt.Errorf("workflow is at version %d after 8 changes, want 8", state.Version)
+	}
+	// setting the timer again may or may not count as a change
+	if stored, err = r.Workflows.SetTimer(ctx, orderID, "wait", wakeAt.Add(time.Hour)); err != nil {
+		return err
+	}
+	if !stored.Equal(wakeAt) {
+		return fmt.Errorf("setting a timer again moved it to %v", stored)
+	}
+
+	state, err = r.Workflows.GetStateByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if state.Status != domain.StatusCompensating || state.Workflow != "conformance" || !state.Timers["wait"].Equal(wakeAt) {
+		return fmt.Errorf("loaded workflow %s is %s of %s with timers %v", orderID, state.Status, state.Workflow, state.Timers)
+	}
+
+	// a child workflow has no order of its own
+	now := time.Now()
+	child := &domain.WorkflowState{
+		OrderID:         domain.ChildWorkflowID(orderID, "child"),
+		RootOrderID:     orderID,
+		ParentOrderID:   orderID,
+		ParentStep:      "child",
+		Workflow:        "conformance",
+		WorkflowVersion: 1,
+		CurrentStep:     "first",
+		Status:          domain.StatusPending,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := r.Workflows.SaveState(ctx, child); err != nil {
+		return err
+	}
+	if got, err = r.Workflows.GetStateByOrderID(ctx, child.OrderID); err != nil {
+		return err
+	}
+	if got == nil || got.RootOrderID != orderID || got.ParentOrderID != orderID {
+		return fmt.Errorf("loaded child workflow is %+v, want one of order %s", got, orderID)
+	}
+
+	// what paused a workflow and the steps it held are kept while it is paused
+	child.Status = domain.StatusPaused
+	child.PausedBy = domain.PausedByStep
+	child.HeldSteps = []domain.Step{"first"}
+	if err := r.Workflows.SaveState(ctx, child); err != nil {
+		return err
+	}
+	child.HeldSteps = append(child.HeldSteps, "second")
+	if err := r.Workflows.SaveState(ctx, child); err != nil {
+		return err
+	}
+	if got, err = r.Workflows.GetStateByOrderID(ctx, child.OrderID); err != nil {
+		return err
+	}
+	if got.PausedBy != domain.PausedByStep || !slices.Equal(got.HeldSteps, []domain.Step{"first", "second"}) {
+		return fmt.Errorf("paused workflow is paused by %q holding %v, want step holding [first second]", got.PausedBy, got.HeldSteps)
+	}
+	got.Status = domain.StatusPending
+	got.PausedBy = ""
+	got.HeldSteps = nil
+	if err := r.Workflows.SaveState(ctx, got); err != nil {
+		return err
+	}
+	if got, err = r.Workflows.GetStateByOrderID(ctx, child.OrderID); err != nil {
+		return err
+	}
+	if got.PausedBy != "" || len(got.HeldSteps) > 0 {
+		return fmt.Errorf("resumed workflow is still paused by %q holding %v", got.PausedBy, got.HeldSteps)
+	}
+
+	// a retry compensating the steps it runs again keeps the step it is from
+	got.Status = domain.StatusCompensating
+	got.PendingCompensations = []domain.Step{"first"}
+	got.RetryFrom = "first"
+	if err := r.Workflows.SaveState(ctx, got); err != nil {
+		return err
+	}
+	if got, err = r.Workflows.GetStateByOrderID(ctx, child.OrderID); err != nil {
+		return err
+	}
+	if got.RetryFrom != "first" {
+		return fmt.Errorf("compensating retry is from step %q, want first", got.RetryFrom)
+	}
+
+	missing := orderID + "-missing"
+	if _, err := r.Workflows.AppendCompensation(ctx, missing, "first"); err == nil {
+		return fmt.Errorf("adding a compensation to a missing workflow succeeded")
+	}
+	if _, err := r.Workflows.SetTimer(ctx, missing, "wait", wakeAt); err == nil {
+		return fmt.Errorf("setting a timer of a missing workflow succeeded")
+	}
+	if ok, err := r.Workflows.UpdateStatus(ctx, missing, domain.StatusPending, domain.StatusFailed); err != nil || ok {
+		return fmt.Errorf("updating the status of a missing workflow returned %v, %v", ok, err)
+	}
+	return nil
+}
+
+func checkWorkflowQueries(ctx context.Context, r Repos, orderID string) error {
+	workflow := orderID // unique to the run, so other workflows are not counted
+	workflows := []struct {
+		version int
+		status  domain.WorkflowStatus
+	}{
+		{1, domain.StatusPending},
+		{1, domain.StatusWaiting},
+		{1, domain.StatusCompleted},
+		{2, domain.StatusCompensating},
+		{2, domain.StatusCompensated},
+		{2, domain.StatusPaused},
+	}
+	ids := make(map[string]domain.WorkflowStatus)
+	for i, w := range workflows {
+		id := fmt.Sprintf("%s-%d", orderID, i)
+		if _, err := saveWorkflow(ctx, r, id, workflow, w.version, w.status); err != nil {
+			return err
+		}
+		ids[id] = w.status
+	}
+
+	usages, err := r.Workflows.GetRunningVersions(ctx)
+	if err != nil {
+		return err
+	}
+	var running []domain.WorkflowVersionUsage
+	for _, u := range usages {
+		if u.Workflow == workflow {
+			running = append(running, *u)
+		}
+	}
+	want := []domain.WorkflowVersionUsage{{Workflow: workflow, Version: 1, Running: 2}, {Workflow: workflow, Version: 2, Running: 2}}
+	if !slices.Equal(running, want) {
+		return fmt.Errorf("running versions are %v, want %v", running, want)
+	}
+
+	stalled := func(timeout time.Duration) ([]domain.WorkflowStatus, error) {
+		states, err := r.Workflows.GetStalledWorkflows(ctx, timeout)
+		if err != nil {
+			return nil, err
+		}
+		var statuses []domain.WorkflowStatus
+		for _, s := range states {
+			if status, ok := ids[s.OrderID]; ok {
+				statuses = append(statuses, status)
+			}
+		}
+		slices.Sort(statuses)
+		return statuses, nil
+	}
+	statuses, err := stalled(time.Hour)
+	if err != nil {
+		return err
+	}
+	if len(statuses) != 0 {
+		return fmt.Errorf("workflows updated just now are stalled: %v", statuses)
+	}
+	if statuses, err = stalled(-time.Hour); err != nil {
+		return err
+	}
+	if !slices.Equal(statuses, []domain.WorkflowStatus{domain.StatusCompensating, domain.StatusPending}) {
+		return fmt.Errorf("stalled workflows are %v, want the pending and the compensating one", statuses)
+	}
+	return nil
+}
+
+func checkWorkflowList(ctx context.Context, r Repos, orderID string) error {
+	// created at a second of the past unique to the run, so that only these
+	// workflows are listed
+	base := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(time.Now().UnixNano()%(1<<50)) * time.Microsecond).Truncate(time.Second)
+	statuses := []domain.WorkflowStatus{domain.StatusPending, domain.StatusCompleted, domain.StatusPending, domain.StatusPending}
+	var ids []string
+	for i, status := range statuses {
+		id := fmt.Sprintf("%s-%d", orderID, i)
+		if err := saveOrder(ctx, r, id); err != nil {
+			return err
+		}
+		// the last two share a creation time and are ordered by order ID
+		createdAt := base.Add(time.Duration(min(i, 2)) * time.Millisecond)
+		state := &domain.WorkflowState{OrderID: id, RootOrderID: id, Workflow: "conformance", WorkflowVersion: 1, CurrentStep: "first", Status: status, CreatedAt: createdAt, UpdatedAt: createdAt}
+		if err := r.Workflows.SaveState(ctx, state); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	list := func(filter domain.WorkflowFilter) ([]string, error) {
+		filter.CreatedAfter, filter.CreatedBefore = base, base.Add(time.Second)
+		states, err := r.Workflows.ListWorkflows(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		var listed []string
+		for _, s := range states {
+			listed = append(listed, s.OrderID)
+		}
+		return listed, nil
+	}
+	listed, err := list(domain.WorkflowFilter{})
+	if err != nil {
+		return err
+	}
+	if want := []string{ids[3], ids[2], ids[1], ids[0]}; !slices.Equal(listed, want) {
+		return fmt.Errorf("listed %v, want %v", listed, want)
+	}
+	if listed, err = list(domain.WorkflowFilter{Statuses: []domain.WorkflowStatus{domain.StatusCompleted}}); err != nil {
+		return err
+	}
+	if !slices.Equal(listed, []string{ids[1]}) {
+		return fmt.Errorf("listed %v as completed, want %v", listed, ids[1:2])
+	}
+
+	filter := domain.WorkflowFilter{Statuses: []domain.WorkflowStatus{domain.StatusPending}, Limit: 2}
+	if listed, err = list(filter); err != nil {
+		return err
+	}
+	if want := []string{ids[3], ids[2]}; !slices.Equal(listed, want) {
+		return fmt.Errorf("first page is %v, want %v", listed, want)
+	}
+	filter.After = &domain.WorkflowCursor{CreatedAt: base.Add(2 * time.Millisecond), OrderID: ids[2]}
+	if listed, err = list(filter); err != nil {
+		return err
+	}
+	if want := []string{ids[0]}; !slices.Equal(listed, want) {
+		return fmt.Errorf("second page is %v, want %v", listed, want)
+	}
+
+	states, err := r.Workflows.ListWorkflows(ctx, domain.WorkflowFilter{CreatedAfter: base.Add(time.Millisecond), CreatedBefore: base.Add(2 * time.Millisecond)})
+	if err != nil {
+		return err
+	}
+	if len(states) != 1 || states[0].OrderID != ids[1] {
+		return fmt.Errorf("listed %d workflows created in the millisecond of %s", len(states), ids[1])
+	}
+	return nil
+}
+
+func checkStepExecutions(ctx context.Context, r Repos, orderID string) error {
+	key := orderID + ":reserve"
+	exec, err := r.Steps.GetExecution(ctx, key)
+	if err != nil {
+		return err
+	}
+	if exec != nil {
+		return fmt.Errorf("got execution %s before saving it", key)
+	}
+	if err := r.Steps.SaveExecution(ctx, &domain.StepExecution{DedupeKey: key, Result: "success", Output: map[string]any{"slot": "A1"}}); err != nil {
+		return err
+	}
+	exec, err = r.Steps.GetExecution(ctx, key)
+	if err != nil {
+		return err
+	}
+	if exec == nil || exec.Result != "success" || exec.Output["slot"] != "A1" || exec.ExecutedAt.IsZero() {
+		return fmt.Errorf("loaded execution is %+v", exec)
+	}
+	if err := r.Steps.SaveExecution(ctx, &domain.StepExecution{DedupeKey: key, Result: "failed"}); err == nil {
+		return fmt.Errorf("saving an execution twice under the same dedupe key succeeded")
+	}
+
+	if err := r.Steps.DeleteExecution(ctx, key); err != nil {
+		return err
+	}
+	if exec, err := r.Steps.GetExecution(ctx, key); err != nil || exec != nil {
+		return fmt.Errorf("got execution %v, %v after deleting it", exec, err)
+	}
+	if err := r.Steps.SaveExecution(ctx, &domain.StepExecution{DedupeKey: key, Result: "failed"}); err != nil {
+		return fmt.Errorf("failed to save an execution again after deleting it: %w", err)
+	}
+	return r.Steps.DeleteExecution(ctx, orderID+":missing")
+}
+
+func checkCompensationExecutions(ctx context.Context, r Repos, orderID string) error {
+	if _, err := saveWorkflow(ctx, r, orderID, "conformance", 1, domain.StatusPending); err != nil {
+		return err
+	}
+	key := orderID + ":release"
+	if exec, err := r.Compensations.GetCompensationExecution(ctx, key); err != nil || exec != nil {
+		return fmt.Errorf("got compensation execution %v, %v before saving it", exec, err)
+	}
+	attempts := []*domain.CompensationExecution{
+		{DedupeKey: key, OrderID: orderID, Compensation: "release", Result: "failed", LastError: "boom"},
+		{DedupeKey: key, OrderID: orderID, Compensation: "release", Result: "success"},
+	}
+	for i, exec := range attempts {
+		claimed, err := r.Compensations.ClaimCompensation(ctx, exec)
+		if err != nil {
+			return err
+		}
+		if !claimed || exec.Attempts != i+1 {
+			return fmt.Errorf("claim of attempt %d returned %v, counted as %d", i+1, claimed, exec.Attempts)
+		}
+		running, err := r.Compensations.GetCompensationExecution(ctx, key)
+		if err != nil {
+			return err
+		}
+		if running == nil || running.Result != "in_progress" {
+			return fmt.Errorf("claimed compensation execution is %+v, want it in progress", running)
+		}
+		if err := r.Compensations.SaveCompensationAttempt(ctx, exec); err != nil {
+			return err
+		}
+		if exec.Attempts != i+1 {
+			return fmt.Errorf("saving attempt %d counted it as %d", i+1, exec.Attempts)
+		}
+	}
+	exec, err := r.Compensations.GetCompensationExecution(ctx, key)
+	if err != nil {
+		return err
+	}
+	if exec == nil || exec.Result != "success" || exec.Attempts != 2 || exec.LastError != "boom" {
+		return fmt.Errorf("loaded compensation execution is %+v, want the last result and the last error", exec)
+	}
+
+	late := &domain.CompensationExecution{DedupeKey: key, OrderID: orderID, Compensation: "release"}
+	if claimed, err := r.Compensations.ClaimCompensation(ctx, late); err != nil || claimed {
+		return fmt.Errorf("claiming a compensation that succeeded returned %v, %v, want false", claimed, err)
+	}
+	late.Result, late.LastError = "failed", "late"
+	if err := r.Compensations.SaveCompensationAttempt(ctx, late); err != nil {
+		return err
+	}
+	if exec, err = r.Compensations.GetCompensationExecution(ctx, key); err != nil {
+		return err
+	}
+	if exec.Result != "success" || exec.Attempts != 2 {
+		return fmt.Errorf("a late failure changed the compensation execution that succeeded to %+v", exec)
+	}
+
+	other := orderID + ":unassign"
+	if err := r.Compensations.SaveCompensationAttempt(ctx, &domain.CompensationExecution{DedupeKey: other, OrderID: orderID, Compensation: "unassign", Result: "success"}); err != nil {
+		return err
+	}
+	execs, err := r.Compensations.GetCompensationExecutionsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for _, e := range execs {
+		keys = append(keys, e.DedupeKey)
+	}
+	if !slices.Equal(keys, []string{key, other}) {
+		return fmt.Errorf("compensation executions of the order are %v, want %v", keys, []string{key, other})
+	}
+
+	if err := r.Compensations.DeleteCompensationExecution(ctx, key); err != nil {
+		return err
+	}
+	if exec, err := r.Compensations.GetCompensationExecution(ctx, key); err != nil || exec != nil {
+		return fmt.Errorf("got compensation execution %v, %v after deleting it", exec, err)
+	}
+	execs, err = r.Compensations.GetCompensationExecutionsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(execs) != 1 || execs[0].DedupeKey != other {
+		return fmt.Errorf("compensation executions of the order after deleting %s are %v, want only %s", key, execs, other)
+	}
+	if err := r.Compensations.SaveCompensationAttempt(ctx, &domain.CompensationExecution{DedupeKey: key, OrderID: orderID, Compensation: "release", Result: "success"}); err != nil {
+		return err
+	}
+	if exec, err := r.Compensations.GetCompensationExecution(ctx, key); err != nil || exec == nil || exec.Attempts != 1 {
+		return fmt.Errorf("compensation execution saved again after deleting it is %+v, %v, want a first attempt", exec, err)
+	}
+	return nil
+}
+
+func checkOutbox(ctx context.Context, r Repos, orderID string) error {
+	processAt := time.Now().Add(time.Minute).Truncate(time.Microsecond)
+	msgs := []*domain.OutboxMessage{
+		{TaskType: "step", Payload: []byte(fmt.Sprintf(`{"order_id":%q}`, orderID))},
+		{TaskType: "timer", Payload: []byte(fmt.Sprintf(`{"order_id":%q}`, orderID)), TaskID: orderID + ":timer", ProcessAt: processAt},
+	}
+	for _, msg := range msgs {
+		if err := r.Outbox.AddMessage(ctx, msg); err != nil {
+			return err
+		}
+		if msg.ID == 0 || msg.CreatedAt.IsZero() {
+			return fmt.Errorf("added message has ID %d, created at %v", msg.ID, msg.CreatedAt)
+		}
+	}
+	if msgs[1].ID <= msgs[0].ID {
+		return fmt.Errorf("message IDs %d and %d do not increase", msgs[0].ID, msgs[1].ID)
+	}
+
+	unsent := func() ([]*domain.OutboxMessage, error) {
+		all, err := r.Outbox.GetUnsentMessages(ctx, 1000)
+		if err != nil {
+			return nil, err
+		}
+		var ours []*domain.OutboxMessage
+		for _, msg := range all {
+			if msg.ID == msgs[0].ID || msg.ID == msgs[1].ID {
+				ours = append(ours, msg)
+			}
+		}
+		return ours, nil
+	}
+	got, err := unsent()
+	if err != nil {
+		return err
+	}
+	if len(got) != 2 || got[0].ID != msgs[0].ID {
+		return fmt.Errorf("got %d of the 2 unsent messages, or out of order", len(got))
+	}
+	if got[1].TaskType != "timer" || got[1].TaskID != msgs[1].TaskID || !got[1].ProcessAt.Equal(processAt) || !samePayload(got[1].Payload, orderID) {
+		return fmt.Errorf("loaded message is %+v", got[1])
+	}
+	if !got[0].ProcessAt.IsZero() || got[0].TaskID != "" {
+		return fmt.Errorf("message to process right away loaded with task ID %q, process at %v", got[0].TaskID, got[0].ProcessAt)
+	}
+
+	if err := r.Outbox.MarkSent(ctx, []int64{msgs[0].ID, msgs[1].ID}); err != nil {
+		return err
+	}
+	if got, err = unsent(); err != nil {
+		return err
+	}
+	if len(got) != 0 {
+		return fmt.Errorf("%d messages are still unsent after marking them sent", len(got))
+	}
+	return nil
+}
+
+// samePayload reports whether payload is the one checkOutbox added for
+// orderID. It is decoded, as a JSONB column does not keep the formatting.
+func samePayload(payload []byte, orderID string) bool {
+	var p struct {
+		OrderID string `json:"order_id"`
+	}
+	return json.Unmarshal(payload, &p) == nil && p.OrderID == orderID
+}
+
+func checkTransitions(ctx context.Context, r Repos, orderID string) error {
+	if _, err := saveWorkflow(ctx, r, orderID, "conformance", 1, domain.StatusPending); err != nil {
+		return err
+	}
+	transitions := []*domain.WorkflowTransition{
+		{OrderID: orderID, ToStep: "first", ToStatus: domain.StatusPending, Cause: "workflow started", Worker: "conformance"},
+		{OrderID: orderID, FromStep: "first", ToStep: "first", FromStatus: domain.StatusPending, ToStatus: domain.StatusCompensating, Cause: "step first failed", Error: "boom", Worker: "conformance"},
+	}
+	for _, t := range transitions {
+		if err := r.Transitions.AddTransition(ctx, t); err != nil {
+			return err
+		}
+		if t.ID == 0 || t.CreatedAt.IsZero() {
+			return fmt.Errorf("added transition has ID %d, created at %v", t.ID, t.CreatedAt)
+		}
+	}
+	got, err := r.Transitions.GetTransitionsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(got) != 2 {
+		return fmt.Errorf("got %d transitions, want 2", len(got))
+	}
+	for i, t := range got {
+		want := transitions[i]
+		if t.ID != want.ID || t.FromStep != want.FromStep || t.ToStep != want.ToStep || t.FromStatus != want.FromStatus || t.ToStatus != want.ToStatus || t.Cause != want.Cause || t.Error != want.Error || t.Worker != want.Worker {
+			return fmt.Errorf("transition %d is %+v, want %+v", i, t, want)
+		}
+	}
+	after, err := r.Transitions.GetTransitionsAfter(ctx, orderID, transitions[0].ID)
+	if err != nil {
+		return err
+	}
+	if len(after) != 1 || after[0].ID != transitions[1].ID {
+		return fmt.Errorf("got %d transitions after the first, want only the second", len(after))
+	}
+	return nil
+}
+
+func checkPauses(ctx context.Context, r Repos, orderID string) error {
+	// steps unique to the run, as pauses are not tied to an order
+	first, second := domain.Step(orderID+"-first"), domain.Step(orderID+"-second")
+	ours := func() ([]*domain.WorkflowPause, error) {
+		pauses, err := r.Pauses.GetPauses(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return slices.DeleteFunc(pauses, func(p *domain.WorkflowPause) bool { return p.Step != first && p.Step != second }), nil
+	}
+
+	for _, p := range []*domain.WorkflowPause{{Step: first, Reason: "incident"}, {Step: second}, {Step: first, Reason: "still down"}} {
+		if err := r.Pauses.AddPause(ctx, p); err != nil {
+			return err
+		}
+		if p.CreatedAt.IsZero() {
+			return fmt.Errorf("added pause of %s has no creation time", p.Step)
+		}
+	}
+	pauses, err := ours()
+	if err != nil {
+		return err
+	}
+	if len(pauses) != 2 || pauses[0].Step != first || pauses[1].Step != second {
+		return fmt.Errorf("got pauses %v, want one of each step, oldest first", pauses)
+	}
+	if pauses[0].Reason != "still down" {
+		return fmt.Errorf("reason is %q, want the one it was added with last", pauses[0].Reason)
+	}
+
+	for _, want := range []bool{true, false} {
+		removed, err := r.Pauses.RemovePause(ctx, first)
+		if err != nil {
+			return err
+		}
+		if removed != want {
+			return fmt.Errorf("removing the pause of %s reported %v, want %v", first, removed, want)
+		}
+	}
+	if _, err := r.Pauses.RemovePause(ctx, second); err != nil {
+		return err
+	}
+	if pauses, err = ours(); err != nil {
+		return err
+	}
+	if len(pauses) != 0 {
+		return fmt.Errorf("got pauses %v after removing them", pauses)
+	}
+	return nil
+}
+
+func checkEvents(ctx context.Context, r Repos, orderID string) error {
+	events := []*domain.WorkflowEvent{
+		{Type: domain.EventWorkflowStarted, Workflow: "conformance", WorkflowVersion: 1, Step: "first", Steps: []domain.Step{"first"}, Status: domain.StatusPending},
+		{Type: domain.EventStepSucceeded, Step: "first", Output: map[string]any{"slot": 7}},
+	}
+	if err := r.Events.AppendEvents(ctx, orderID, 0, events); err != nil {
+		return err
+	}
+	if events[1].Sequence != 2 || events[1].OrderID != orderID {
+		return fmt.Errorf("appended event is number %d of order %s, want 2 of %s", events[1].Sequence, events[1].OrderID, orderID)
+	}
+	late := []*domain.WorkflowEvent{{Type: domain.EventStatusChanged, Status: domain.StatusFailed}}
+	if err := r.Events.AppendEvents(ctx, orderID, 1, late); !errors.Is(err, domain.ErrVersionConflict) {
+		return fmt.Errorf("appending at a stale version returned %v, want a version conflict", err)
+	}
+
+	got, err := r.Events.GetEvents(ctx, orderID, 1)
+	if err != nil {
+		return err
+	}
+	if len(got) != 1 || got[0].Sequence != 2 || got[0].Type != domain.EventStepSucceeded || got[0].Output["slot"] != float64(7) {
+		return fmt.Errorf("events after 1 are %v", got)
+	}
+	if got, err = r.Events.GetEvents(ctx, orderID, 0); err != nil {
+		return err
+	}
+	state, err := domain.FoldEvents(nil, got)
+	if err != nil {
+		return err
+	}
+	if state.Version != 2 || !slices.Equal(state.CompletedSteps, []domain.Step{"first"}) {
+		return fmt.Errorf("folded stream is at version %d with completed steps %v", state.Version, state.CompletedSteps)
+	}
+
+	ids, err := r.Events.GetOrderIDs(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(ids, orderID) {
+		return fmt.Errorf("order IDs with events do not include %s", orderID)
+	}
+
+	if snapshot, err := r.Events.GetSnapshot(ctx, orderID); err != nil || snapshot != nil {
+		return fmt.Errorf("got snapshot %v, %v before saving one", snapshot, err)
+	}
+	if err := r.Events.SaveSnapshot(ctx, state); err != nil {
+		return err
+	}
+	snapshot, err := r.Events.GetSnapshot(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil || snapshot.Version != 2 || snapshot.StepOutputs["first"]["slot"] != float64(7) {
+		return fmt.Errorf("loaded snapshot is %+v", snapshot)
+	}
+	return nil
+}
+
+func checkTransactions(ctx context.Context, r Repos, orderID string) error {
+	failed := errors.New("rolled back")
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := saveOrder(ctx, r, orderID); err != nil {
+			return err
+		}
+		order, err := r.Orders.GetOrderByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return fmt.Errorf("order saved in the transaction is not visible in it")
+		}
+		return r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return failed
+		})
+	})
+	if !errors.Is(err, failed) {
+		return fmt.Errorf("transaction returned %v, want the error of the nested one", err)
+	}
+	order, err := r.Orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		return fmt.Errorf("order saved in a failed transaction was kept")
+	}
+
+	err = r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return saveOrder(ctx, r, orderID)
+	})
+	if err != nil {
+		return err
+	}
+	if order, err = r.Orders.GetOrderByID(ctx, orderID); err != nil {
+		return err
+	}
+	if order == nil {
+		return fmt.Errorf("order saved in a committed transaction is missing")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package conformance checks that an implementation of the domain
+// repositories behaves like the Postgres one the engine was written against.
+// Every implementation must pass it, from a test that calls Run.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// Repos are the repositories under test. They must share one store, as the
+// workflows, executions and transitions of an order refer to the order.
+type Repos struct {
+	Orders        domain.OrderRepo
+	Workflows     domain.WorkflowRepo
+	Agents        domain.AgentRepo
+	Steps         domain.StepExecutionRepo
+	Compensations domain.CompensationExecutionRepo
+	Outbox        domain.OutboxRepo
+	Transitions   domain.WorkflowTransitionRepo
+	Events        domain.WorkflowEventRepo
+	Pauses        domain.PauseRepo
+	Transactor    domain.Transactor
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context, r Repos, orderID string) error
+}
+
+var checks = []check{
+	{"orders", checkOrders},
+	{"agents", checkAgents},
+	{"workflows", checkWorkflows},
+	{"workflow queries", checkWorkflowQueries},
+	{"workflow list", checkWorkflowList},
+	{"step executions", checkStepExecutions},
+	{"compensation executions", checkCompensationExecutions},
+	{"outbox", checkOutbox},
+	{"transitions", checkTransitions},
+	{"events", checkEvents},
+	{"pauses", checkPauses},
+	{"transactions", checkTransactions},
+}
+
+// Run runs every check against r, each as a subtest of t. Checks only touch
+// orders they create, under IDs unique to the run, so a database can be
+// checked more than once; it should still not be one in use.
+func Run(t *testing.T, r Repos) {
+	run := fmt.Sprintf("conformance-%d", time.Now().UnixNano())
+	for i, c := range checks {
+		orderID := fmt.Sprintf("%s-%d", run, i)
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.run(t.Context(), r, orderID); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func saveOrder(ctx context.Context, r Repos, orderID string) error {
+	now := time.Now()
+	order := &domain.Order{ID: orderID, Status: "pending", CreatedAt: now, UpdatedAt: now}
+	return r.Orders.SaveOrder(ctx, order)
+}
+
+func saveWorkflow(ctx context.Context, r Repos, orderID, workflow string, version int, status domain.WorkflowStatus) (*domain.WorkflowState, error) {
+	if err := saveOrder(ctx, r, orderID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	state := &domain.WorkflowState{
+		OrderID:         orderID,
+		RootOrderID:     orderID,
+		Workflow:        workflow,
+		WorkflowVersion: version,
+		CurrentStep:     "first",
+		ActiveSteps:     []domain.Step{"first", "second"},
+		Status:          status,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := r.Workflows.SaveState(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
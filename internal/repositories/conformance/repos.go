@@ -0,0 +1,56 @@
+package conformance
+
+import (
+	"database/sql"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories/memory"
+)
+
+// MemoryRepos returns the in-memory repositories of store.
+func MemoryRepos(store *memory.Store) Repos {
+	return Repos{
+		Orders:        memory.NewOrderRepo(store),
+		Workflows:     memory.NewWorkflowRepo(store),
+		Agents:        memory.NewAgentRepo(store),
+		Steps:         memory.NewStepExecutionRepo(store),
+		Compensations: memory.NewCompensationExecutionRepo(store),
+		Outbox:        memory.NewOutboxRepo(store),
+		Transitions:   memory.NewWorkflowTransitionRepo(store),
+		Events:        memory.NewWorkflowEventRepo(store),
+		Pauses:        memory.NewPauseRepo(store),
+		Transactor:    memory.NewTransactor(store),
+	}
+}
+
+// PostgresRepos returns the Postgres repositories of db.
+func PostgresRepos(db *sql.DB) Repos {
+	return Repos{
+		Orders:        repositories.NewOrderRepo(db),
+		Workflows:     repositories.NewWorkflowRepo(db),
+		Agents:        repositories.NewAgentRepo(db),
+		Steps:         repositories.NewStepExecutionRepo(db),
+		Compensations: repositories.NewCompensationExecutionRepo(db),
+		Outbox:        repositories.NewOutboxRepo(db),
+		Transitions:   repositories.NewWorkflowTransitionRepo(db),
+		Events:        repositories.NewWorkflowEventRepo(db),
+		Pauses:        repositories.NewPauseRepo(db),
+		Transactor:    repositories.NewTransactor(db),
+	}
+}
+
+// MemoryEventRepos returns the in-memory repositories of store, with
+// workflows stored as event streams.
+func MemoryEventRepos(store *memory.Store) Repos {
+	r := MemoryRepos(store)
+	r.Workflows = memory.NewEventSourcedWorkflowRepo(store)
+	return r
+}
+
+// PostgresEventRepos returns the Postgres repositories of db, with workflows
+// stored as event streams, as with WORKFLOW_STORE=events.
+func PostgresEventRepos(db *sql.DB) Repos {
+	r := PostgresRepos(db)
+	r.Workflows = repositories.NewEventSourcedWorkflowRepo(db)
+	return r
+}
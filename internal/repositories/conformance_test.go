@@ -0,0 +1,36 @@
+package repositories_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories/conformance"
+)
+
+// TestConformance runs against the migrated database of TEST_DATABASE_DSN,
+// which must not be one in use, and is skipped without it.
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.PostgresRepos(testDB(t)))
+}
+
+func TestEventStoreConformance(t *testing.T) {
+	conformance.Run(t, conformance.PostgresEventRepos(testDB(t)))
+}
+
+func testDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("failed to ping Postgres: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// snapshotInterval is the number of events after which the state of a
+// workflow is snapshotted, so loading it folds at most that many events.
+const snapshotInterval = 50
+
+type eventSourcedWorkflowRepo struct {
+	db         *sql.DB
+	events     domain.WorkflowEventRepo
+	projection *postgresWorkflowRepo
+}
+
+func NewEventSourcedWorkflowRepo(db *sql.DB) domain.EventSourcedWorkflowRepo {
+	return &eventSourcedWorkflowRepo{
+		db:         db,
+		events:     NewWorkflowEventRepo(db),
+		projection: &postgresWorkflowRepo{db: db},
+	}
+}
+
+func (r *eventSourcedWorkflowRepo) SaveState(ctx context.Context, state *domain.WorkflowState) error {
+	return NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, state.OrderID)
+		if err != nil {
+			return err
+		}
+		if current == nil && state.Version != 0 || current != nil && current.Version != state.Version {
+			return &domain.VersionConflictError{OrderID: state.OrderID, Version: state.Version}
+		}
+		events, err := domain.StateEvents(current, state)
+		if err != nil {
+			return err
+		}
+		saved, err := r.append(ctx, current, state.OrderID, events)
+		if err != nil {
+			return err
+		}
+		state.Version = saved.Version
+		return nil
+	})
+}
+
+func (r *eventSourcedWorkflowRepo) GetStateByOrderID(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	return r.load(ctx, orderID)
+}
+
+func (r *eventSourcedWorkflowRepo) GetStalledWorkflows(ctx context.Context, timeout time.Duration) ([]*domain.WorkflowState, error) {
+	return r.projection.GetStalledWorkflows(ctx, timeout)
+}
+
+func (r *eventSourcedWorkflowRepo) CompleteStep(ctx context.Context, orderID string, step domain.Step, output map[string]any) (*domain.WorkflowState, error) {
+	// the output is returned as the events table gives it back
+	data, err := marshalJSON(output, "{}")
+	if err != nil {
+		return nil, err
+	}
+	output = nil
+	if err := unmarshalJSON(data, &output); err != nil {
+		return nil, err
+	}
+	var state *domain.WorkflowState
+	err = NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil || current == nil || !slices.Contains(current.ActiveSteps, step) {
+			return err
+		}
+		state, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventStepSucceeded, Step: step, Output: output}})
+		return err
+	})
+	return state, err
+}
+
+func (r *eventSourcedWorkflowRepo) AppendCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		state, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventCompensationScheduled, Step: step}})
+		return err
+	})
+	return state, err
+}
+
+func (r *eventSourcedWorkflowRepo) PopCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil || current == nil || len(current.PendingCompensations) == 0 || current.PendingCompensations[0] != step {
+			return err
+		}
+		state, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventCompensationSucceeded, Step: step}})
+		return err
+	})
+	return state, err
+}
+
+func (r *eventSourcedWorkflowRepo) UpdateStatus(ctx context.Context, orderID string, from, to domain.WorkflowStatus) (bool, error) {
+	updated := false
+	err := NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil || current == nil || current.Status != from {
+			return err
+		}
+		_, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventStatusChanged, Status: to}})
+		updated = err == nil
+		return err
+	})
+	return updated, err
+}
+
+func (r *eventSourcedWorkflowRepo) SetTimer(ctx context.Context, orderID string, step domain.Step, wakeAt time.Time) (time.Time, error) {
+	stored := wakeAt
+	err := NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		if existing, ok := current.Timers[step]; ok {
+			stored = existing
+			return nil
+		}
+		_, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventTimerSet, Step: step, WakeAt: wakeAt}})
+		return err
+	})
+	return stored, err
+}
+
+func (r *eventSourcedWorkflowRepo) GetRunningVersions(ctx context.Context) ([]*domain.WorkflowVersionUsage, error) {
+	return r.projection.GetRunningVersions(ctx)
+}
+
+func (r *eventSourcedWorkflowRepo) ListWorkflows(ctx context.Context, filter domain.WorkflowFilter) ([]*domain.WorkflowState, error) {
+	return r.projection.ListWorkflows(ctx, filter)
+}
+
+func (r *eventSourcedWorkflowRepo) RebuildProjection(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		events, err := r.events.GetEvents(ctx, orderID, 0)
+		if err != nil {
+			return err
+		}
+		if state, err = domain.FoldEvents(nil, events); err != nil {
+			return err
+		}
+		if state == nil {
+			return fmt.Errorf("no events for order %s", orderID)
+		}
+		if err := r.events.SaveSnapshot(ctx, state); err != nil {
+			return err
+		}
+		return r.projection.saveProjection(ctx, state)
+	})
+	return state, err
+}
+
+// load folds the events of orderID since its latest snapshot into the
+// snapshot. It returns nil if the workflow has no events.
+func (r *eventSourcedWorkflowRepo) load(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	snapshot, err := r.events.GetSnapshot(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var after int64
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+	events, err := r.events.GetEvents(ctx, orderID, after)
+	if err != nil {
+		return nil, err
+	}
+	return domain.FoldEvents(snapshot, events)
+}
+
+// append adds events to the stream of orderID, which is at current, and
+// updates the projection, and the snapshot once it is snapshotInterval events
+// behind. It returns the resulting state.
+func (r *eventSourcedWorkflowRepo) append(ctx context.Context, current *domain.WorkflowState, orderID string, events []*domain.WorkflowEvent) (*domain.WorkflowState, error) {
+	if len(events) == 0 {
+		return current, nil
+	}
+	var version int64
+	if current != nil {
+		version = current.Version
+	}
+	if err := r.events.AppendEvents(ctx, orderID, version, events); err != nil {
+		return nil, err
+	}
+	state, err := domain.FoldEvents(current, events)
+	if err != nil {
+		return nil, err
+	}
+	if state.Version/snapshotInterval > version/snapshotInterval {
+		if err := r.events.SaveSnapshot(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.projection.saveProjection(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// marshalJSON encodes v for a JSONB column. Nil maps and slices are stored as
+// empty values rather than JSON null.
+func marshalJSON(v any, empty string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON column: %w", err)
+	}
+	if string(data) == "null" {
+		return []byte(empty), nil
+	}
+	return data, nil
+}
+
+func unmarshalJSON(data []byte, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode JSON column: %w", err)
+	}
+	return nil
+}
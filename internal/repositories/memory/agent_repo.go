@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryAgentRepo struct {
+	store *Store
+}
+
+func NewAgentRepo(store *Store) domain.AgentRepo {
+	return &memoryAgentRepo{store: store}
+}
+
+func (r *memoryAgentRepo) AssignAgent(ctx context.Context, orderID, agentID string) error {
+	return r.store.do(ctx, func(d *data) error {
+		agents := d.agents[orderID]
+		if slices.Contains(agents, agentID) {
+			return nil
+		}
+		d.agents[orderID] = append(slices.Clip(agents), agentID)
+		return nil
+	})
+}
+
+func (r *memoryAgentRepo) GetAgentsByOrderID(ctx context.Context, orderID string) ([]string, error) {
+	var agentIDs []string
+	err := r.store.do(ctx, func(d *data) error {
+		agentIDs = slices.Clone(d.agents[orderID])
+		return nil
+	})
+	return agentIDs, err
+}
+
+func (r *memoryAgentRepo) UnassignAgents(ctx context.Context, orderID string) error {
+	return r.store.do(ctx, func(d *data) error {
+		delete(d.agents, orderID)
+		return nil
+	})
+}
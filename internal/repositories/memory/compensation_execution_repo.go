@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryCompensationExecutionRepo struct {
+	store *Store
+}
+
+func NewCompensationExecutionRepo(store *Store) domain.CompensationExecutionRepo {
+	return &memoryCompensationExecutionRepo{store: store}
+}
+
+func (r *memoryCompensationExecutionRepo) GetCompensationExecution(ctx context.Context, dedupeKey string) (*domain.CompensationExecution, error) {
+	var exec *domain.CompensationExecution
+	err := r.store.do(ctx, func(d *data) error {
+		if stored, ok := d.compensations[dedupeKey]; ok {
+			e := *stored
+			exec = &e
+		}
+		return nil
+	})
+	return exec, err
+}
+
+func (r *memoryCompensationExecutionRepo) ClaimCompensation(ctx context.Context, exec *domain.CompensationExecution) (bool, error) {
+	var claimed bool
+	err := r.store.do(ctx, func(d *data) error {
+		existing, ok := d.compensations[exec.DedupeKey]
+		if ok && existing.Result == "success" {
+			return nil
+		}
+		saved := newCompensationExecution(exec, "in_progress")
+		if ok {
+			saved = *existing
+			saved.Result = "in_progress"
+			saved.Attempts++
+			saved.UpdatedAt = time.Now()
+		} else {
+			d.compensationKeys = append(d.compensationKeys, exec.DedupeKey)
+		}
+		d.compensations[exec.DedupeKey] = &saved
+		exec.Attempts = saved.Attempts
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *memoryCompensationExecutionRepo) SaveCompensationAttempt(ctx context.Context, exec *domain.CompensationExecution) error {
+	return r.store.do(ctx, func(d *data) error {
+		saved := newCompensationExecution(exec, exec.Result)
+		saved.LastError = exec.LastError
+		if existing, ok := d.compensations[exec.DedupeKey]; ok {
+			saved = *existing
+			if saved.Result != "success" {
+				saved.Result = exec.Result
+			}
+			if exec.LastError != "" {
+				saved.LastError = exec.LastError
+			}
+			saved.UpdatedAt = time.Now()
+		} else {
+			d.compensationKeys = append(d.compensationKeys, exec.DedupeKey)
+		}
+		d.compensations[exec.DedupeKey] = &saved
+		exec.Attempts = saved.Attempts
+		return nil
+	})
+}
+
+// newCompensationExecution returns the entry of a first attempt of exec.
+func newCompensationExecution(exec *domain.CompensationExecution, result string) domain.CompensationExecution {
+	now := time.Now()
+	return domain.CompensationExecution{
+		DedupeKey:    exec.DedupeKey,
+		OrderID:      exec.OrderID,
+		Compensation: exec.Compensation,
+		Result:       result,
+		Attempts:     1,
+		StartedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func (r *memoryCompensationExecutionRepo) GetCompensationExecutionsByOrderID(ctx context.Context, orderID string) ([]*domain.CompensationExecution, error) {
+	var execs []*domain.CompensationExecution
+	err := r.store.do(ctx, func(d *data) error {
+		for _, key := range d.compensationKeys {
+			if stored := d.compensations[key]; stored.OrderID == orderID {
+				e := *stored
+				execs = append(execs, &e)
+			}
+		}
+		return nil
+	})
+	return execs, err
+}
+
+func (r *memoryCompensationExecutionRepo) DeleteCompensationExecution(ctx context.Context, dedupeKey string) error {
+	return r.store.do(ctx, func(d *data) error {
+		if _, ok := d.compensations[dedupeKey]; !ok {
+			return nil
+		}
+		delete(d.compensations, dedupeKey)
+		d.compensationKeys = slices.DeleteFunc(slices.Clone(d.compensationKeys), func(key string) bool { return key == dedupeKey })
+		return nil
+	})
+}
@@ -0,0 +1,16 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories/conformance"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories/memory"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.MemoryRepos(memory.NewStore()))
+}
+
+func TestEventStoreConformance(t *testing.T) {
+	conformance.Run(t, conformance.MemoryEventRepos(memory.NewStore()))
+}
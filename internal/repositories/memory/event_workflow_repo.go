@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// snapshotInterval is the number of events after which the state of a
+// workflow is snapshotted, as in the Postgres event store.
+const snapshotInterval = 50
+
+type memoryEventSourcedWorkflowRepo struct {
+	store      *Store
+	events     domain.WorkflowEventRepo
+	projection *memoryWorkflowRepo
+}
+
+func NewEventSourcedWorkflowRepo(store *Store) domain.EventSourcedWorkflowRepo {
+	return &memoryEventSourcedWorkflowRepo{
+		store:      store,
+		events:     NewWorkflowEventRepo(store),
+		projection: &memoryWorkflowRepo{store: store},
+	}
+}
+
+func (r *memoryEventSourcedWorkflowRepo) SaveState(ctx context.Context, state *domain.WorkflowState) error {
+	return NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, state.OrderID)
+		if err != nil {
+			return err
+		}
+		if current == nil && state.Version != 0 || current != nil && current.Version != state.Version {
+			return &domain.VersionConflictError{OrderID: state.OrderID, Version: state.Version}
+		}
+		events, err := domain.StateEvents(current, state)
+		if err != nil {
+			return err
+		}
+		saved, err := r.append(ctx, current, state.OrderID, events)
+		if err != nil {
+			return err
+		}
+		state.Version = saved.Version
+		return nil
+	})
+}
+
+func (r *memoryEventSourcedWorkflowRepo) GetStateByOrderID(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	return r.load(ctx, orderID)
+}
+
+func (r *memoryEventSourcedWorkflowRepo) GetStalledWorkflows(ctx context.Context, timeout time.Duration) ([]*domain.WorkflowState, error) {
+	return r.projection.GetStalledWorkflows(ctx, timeout)
+}
+
+func (r *memoryEventSourcedWorkflowRepo) CompleteStep(ctx context.Context, orderID string, step domain.Step, output map[string]any) (*domain.WorkflowState, error) {
+	output, err := copyJSON(output, "{}")
+	if err != nil {
+		return nil, err
+	}
+	var state *domain.WorkflowState
+	err = NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil || current == nil || !slices.Contains(current.ActiveSteps, step) {
+			return err
+		}
+		state, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventStepSucceeded, Step: step, Output: output}})
+		return err
+	})
+	return state, err
+}
+
+func (r *memoryEventSourcedWorkflowRepo) AppendCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		state, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventCompensationScheduled, Step: step}})
+		return err
+	})
+	return state, err
+}
+
+func (r *memoryEventSourcedWorkflowRepo) PopCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil || current == nil || len(current.PendingCompensations) == 0 || current.PendingCompensations[0] != step {
+			return err
+		}
+		state, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventCompensationSucceeded, Step: step}})
+		return err
+	})
+	return state, err
+}
+
+func (r *memoryEventSourcedWorkflowRepo) UpdateStatus(ctx context.Context, orderID string, from, to domain.WorkflowStatus) (bool, error) {
+	updated := false
+	err := NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil || current == nil || current.Status != from {
+			return err
+		}
+		_, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventStatusChanged, Status: to}})
+		updated = err == nil
+		return err
+	})
+	return updated, err
+}
+
+func (r *memoryEventSourcedWorkflowRepo) SetTimer(ctx context.Context, orderID string, step domain.Step, wakeAt time.Time) (time.Time, error) {
+	stored := wakeAt.UTC()
+	err := NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := r.load(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		if existing, ok := current.Timers[step]; ok {
+			stored = existing
+			return nil
+		}
+		_, err = r.append(ctx, current, orderID, []*domain.WorkflowEvent{{Type: domain.EventTimerSet, Step: step, WakeAt: stored}})
+		return err
+	})
+	return stored, err
+}
+
+func (r *memoryEventSourcedWorkflowRepo) GetRunningVersions(ctx context.Context) ([]*domain.WorkflowVersionUsage, error) {
+	return r.projection.GetRunningVersions(ctx)
+}
+
+func (r *memoryEventSourcedWorkflowRepo) ListWorkflows(ctx context.Context, filter domain.WorkflowFilter) ([]*domain.WorkflowState, error) {
+	return r.projection.ListWorkflows(ctx, filter)
+}
+
+func (r *memoryEventSourcedWorkflowRepo) RebuildProjection(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := NewTransactor(r.store).WithinTransaction(ctx, func(ctx context.Context) error {
+		events, err := r.events.GetEvents(ctx, orderID, 0)
+		if err != nil {
+			return err
+		}
+		if state, err = domain.FoldEvents(nil, events); err != nil {
+			return err
+		}
+		if state == nil {
+			return fmt.Errorf("no events for order %s", orderID)
+		}
+		if err := r.events.SaveSnapshot(ctx, state); err != nil {
+			return err
+		}
+		return r.projection.saveProjection(ctx, state)
+	})
+	return state, err
+}
+
+// load folds the events of orderID since its latest snapshot into the
+// snapshot. It returns nil if the workflow has no events.
+func (r *memoryEventSourcedWorkflowRepo) load(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	snapshot, err := r.events.GetSnapshot(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var after int64
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+	events, err := r.events.GetEvents(ctx, orderID, after)
+	if err != nil {
+		return nil, err
+	}
+	return domain.FoldEvents(snapshot, events)
+}
+
+// append adds events to the stream of orderID, which is at current, and
+// updates the projection, and the snapshot once it is snapshotInterval events
+// behind. It returns the resulting state.
+func (r *memoryEventSourcedWorkflowRepo) append(ctx context.Context, current *domain.WorkflowState, orderID string, events []*domain.WorkflowEvent) (*domain.WorkflowState, error) {
+	if len(events) == 0 {
+		return current, nil
+	}
+	var version int64
+	if current != nil {
+		version = current.Version
+	}
+	if err := r.events.AppendEvents(ctx, orderID, version, events); err != nil {
+		return nil, err
+	}
+	state, err := domain.FoldEvents(current, events)
+	if err != nil {
+		return nil, err
+	}
+	if state.Version/snapshotInterval > version/snapshotInterval {
+		if err := r.events.SaveSnapshot(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.projection.saveProjection(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
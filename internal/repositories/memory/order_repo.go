@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryOrderRepo struct {
+	store *Store
+}
+
+func NewOrderRepo(store *Store) domain.OrderRepo {
+	return &memoryOrderRepo{store: store}
+}
+
+func (r *memoryOrderRepo) SaveOrder(ctx context.Context, order *domain.Order) error {
+	return r.store.do(ctx, func(d *data) error {
+		saved := *order
+		if existing, ok := d.orders[order.ID]; ok {
+			// like the upsert, only the status of an existing order changes
+			saved = *existing
+			saved.Status = order.Status
+		} else {
+			orderData, err := copyJSON(order.Data, "{}")
+			if err != nil {
+				return err
+			}
+			saved.Data = orderData
+		}
+		saved.UpdatedAt = time.Now()
+		d.orders[order.ID] = &saved
+		return nil
+	})
+}
+
+func (r *memoryOrderRepo) GetOrderByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	var order *domain.Order
+	err := r.store.do(ctx, func(d *data) error {
+		stored, ok := d.orders[orderID]
+		if !ok {
+			return nil
+		}
+		orderData, err := copyJSON(stored.Data, "{}")
+		if err != nil {
+			return err
+		}
+		o := *stored
+		o.Data = orderData
+		order = &o
+		return nil
+	})
+	return order, err
+}
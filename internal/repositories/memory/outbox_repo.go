@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryOutboxRepo struct {
+	store *Store
+}
+
+func NewOutboxRepo(store *Store) domain.OutboxRepo {
+	return &memoryOutboxRepo{store: store}
+}
+
+func (r *memoryOutboxRepo) AddMessage(ctx context.Context, msg *domain.OutboxMessage) error {
+	return r.store.do(ctx, func(d *data) error {
+		d.lastOutboxID++
+		saved := *msg
+		saved.ID = d.lastOutboxID
+		saved.Payload = slices.Clone(msg.Payload)
+		saved.CreatedAt = time.Now()
+		saved.SentAt = nil
+		d.outbox = append(d.outbox, &saved)
+		msg.ID, msg.CreatedAt = saved.ID, saved.CreatedAt
+		return nil
+	})
+}
+
+// GetUnsentMessages needs no locking of its own: a transaction holds the
+// whole store until it ends.
+func (r *memoryOutboxRepo) GetUnsentMessages(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	var msgs []*domain.OutboxMessage
+	err := r.store.do(ctx, func(d *data) error {
+		for _, stored := range d.outbox {
+			if len(msgs) == limit {
+				break
+			}
+			if stored.SentAt != nil {
+				continue
+			}
+			msg := *stored
+			msg.Payload = slices.Clone(stored.Payload)
+			msgs = append(msgs, &msg)
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+func (r *memoryOutboxRepo) MarkSent(ctx context.Context, ids []int64) error {
+	return r.store.do(ctx, func(d *data) error {
+		now := time.Now()
+		for i, stored := range d.outbox {
+			if slices.Contains(ids, stored.ID) {
+				sent := *stored
+				sent.SentAt = &now
+				d.outbox[i] = &sent
+			}
+		}
+		return nil
+	})
+}
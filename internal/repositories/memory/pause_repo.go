@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryPauseRepo struct {
+	store *Store
+}
+
+func NewPauseRepo(store *Store) domain.PauseRepo {
+	return &memoryPauseRepo{store: store}
+}
+
+func (r *memoryPauseRepo) AddPause(ctx context.Context, p *domain.WorkflowPause) error {
+	return r.store.do(ctx, func(d *data) error {
+		saved := *p
+		saved.CreatedAt = time.Now()
+		if existing, ok := d.pauses[p.Step]; ok {
+			saved.CreatedAt = existing.CreatedAt
+		}
+		d.pauses[p.Step] = &saved
+		p.CreatedAt = saved.CreatedAt
+		return nil
+	})
+}
+
+func (r *memoryPauseRepo) RemovePause(ctx context.Context, step domain.Step) (bool, error) {
+	var removed bool
+	err := r.store.do(ctx, func(d *data) error {
+		_, removed = d.pauses[step]
+		delete(d.pauses, step)
+		return nil
+	})
+	return removed, err
+}
+
+func (r *memoryPauseRepo) GetPauses(ctx context.Context) ([]*domain.WorkflowPause, error) {
+	var pauses []*domain.WorkflowPause
+	err := r.store.do(ctx, func(d *data) error {
+		for _, stored := range d.pauses {
+			p := *stored
+			pauses = append(pauses, &p)
+		}
+		return nil
+	})
+	slices.SortFunc(pauses, func(a, b *domain.WorkflowPause) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.Step, b.Step))
+	})
+	return pauses, err
+}
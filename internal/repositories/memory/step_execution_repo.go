@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryStepExecutionRepo struct {
+	store *Store
+}
+
+func NewStepExecutionRepo(store *Store) domain.StepExecutionRepo {
+	return &memoryStepExecutionRepo{store: store}
+}
+
+func (r *memoryStepExecutionRepo) GetExecution(ctx context.Context, dedupeKey string) (*domain.StepExecution, error) {
+	var exec *domain.StepExecution
+	err := r.store.do(ctx, func(d *data) error {
+		stored, ok := d.steps[dedupeKey]
+		if !ok {
+			return nil
+		}
+		output, err := copyJSON(stored.Output, "{}")
+		if err != nil {
+			return err
+		}
+		e := *stored
+		e.Output = output
+		exec = &e
+		return nil
+	})
+	return exec, err
+}
+
+func (r *memoryStepExecutionRepo) SaveExecution(ctx context.Context, exec *domain.StepExecution) error {
+	return r.store.do(ctx, func(d *data) error {
+		if _, ok := d.steps[exec.DedupeKey]; ok {
+			return fmt.Errorf("failed to save execution: dedupe key %s already exists", exec.DedupeKey)
+		}
+		output, err := copyJSON(exec.Output, "{}")
+		if err != nil {
+			return err
+		}
+		d.steps[exec.DedupeKey] = &domain.StepExecution{
+			DedupeKey:  exec.DedupeKey,
+			Result:     exec.Result,
+			Output:     output,
+			ExecutedAt: time.Now(),
+		}
+		return nil
+	})
+}
+
+func (r *memoryStepExecutionRepo) DeleteExecution(ctx context.Context, dedupeKey string) error {
+	return r.store.do(ctx, func(d *data) error {
+		delete(d.steps, dedupeKey)
+		return nil
+	})
+}
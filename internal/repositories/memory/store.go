@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// Store holds the data of the in-memory repositories. Repositories built on
+// the same Store see each other's writes and take part in the same
+// transactions, like the Postgres repositories of one database.
+type Store struct {
+	mu   sync.Mutex
+	data *data
+}
+
+func NewStore() *Store {
+	return &Store{data: &data{
+		orders:        make(map[string]*domain.Order),
+		agents:        make(map[string][]string),
+		workflows:     make(map[string]*domain.WorkflowState),
+		steps:         make(map[string]*domain.StepExecution),
+		compensations: make(map[string]*domain.CompensationExecution),
+		events:        make(map[string][]*domain.WorkflowEvent),
+		snapshots:     make(map[string]*domain.WorkflowState),
+		pauses:        make(map[domain.Step]*domain.WorkflowPause),
+	}}
+}
+
+// data is the content of a Store. Stored values are never changed in place,
+// only replaced, so a shallow copy is enough to roll a transaction back.
+type data struct {
+	orders           map[string]*domain.Order
+	agents           map[string][]string // agent IDs by order ID
+	workflows        map[string]*domain.WorkflowState
+	steps            map[string]*domain.StepExecution
+	compensations    map[string]*domain.CompensationExecution
+	compensationKeys []string // dedupe keys of compensations, in insertion order
+	outbox           []*domain.OutboxMessage
+	transitions      []*domain.WorkflowTransition
+	events           map[string][]*domain.WorkflowEvent
+	snapshots        map[string]*domain.WorkflowState
+	pauses           map[domain.Step]*domain.WorkflowPause
+	lastOutboxID     int64
+	lastTransitionID int64
+}
+
+func (d *data) clone() *data {
+	c := *d
+	c.orders = maps.Clone(d.orders)
+	c.agents = maps.Clone(d.agents)
+	c.workflows = maps.Clone(d.workflows)
+	c.steps = maps.Clone(d.steps)
+	c.compensations = maps.Clone(d.compensations)
+	c.compensationKeys = slices.Clone(d.compensationKeys)
+	c.outbox = slices.Clone(d.outbox)
+	c.transitions = slices.Clone(d.transitions)
+	c.events = maps.Clone(d.events)
+	c.snapshots = maps.Clone(d.snapshots)
+	c.pauses = maps.Clone(d.pauses)
+	return &c
+}
+
+type txKey struct{}
+
+// do runs fn on the data of s, in the transaction running in ctx if any.
+func (s *Store) do(ctx context.Context, fn func(d *data) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !s.inTransaction(ctx) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return fn(s.data)
+}
+
+func (s *Store) inTransaction(ctx context.Context) bool {
+	tx, _ := ctx.Value(txKey{}).(*Store)
+	return tx == s
+}
+
+type memoryTransactor struct {
+	store *Store
+}
+
+// NewTransactor returns a Transactor for the repositories of store.
+// Transactions hold store for as long as they run, so they are serializable;
+// a repository called without the context of a running transaction waits
+// until it ends.
+func NewTransactor(store *Store) domain.Transactor {
+	return &memoryTransactor{store: store}
+}
+
+func (t *memoryTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := t.store
+	if s.inTransaction(ctx) {
+		return fn(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.data.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			s.data = saved
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, s)); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// copyJSON returns a deep copy of v as a JSONB column gives it back: nil
+// becomes the empty value and numbers become float64.
+func copyJSON[T any](v T, empty string) (T, error) {
+	var c T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return c, fmt.Errorf("failed to encode JSON column: %w", err)
+	}
+	if string(data) == "null" {
+		data = []byte(empty)
+	}
+	if len(data) == 0 {
+		return c, nil
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("failed to decode JSON column: %w", err)
+	}
+	return c, nil
+}
+
+// copyState returns a copy of state that shares nothing with it.
+func copyState(state *domain.WorkflowState) (*domain.WorkflowState, error) {
+	c := state.Clone()
+	outputs, err := copyJSON(state.StepOutputs, "{}")
+	if err != nil {
+		return nil, err
+	}
+	c.StepOutputs = outputs
+	return c, nil
+}
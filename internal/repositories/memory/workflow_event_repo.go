@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryWorkflowEventRepo struct {
+	store *Store
+}
+
+func NewWorkflowEventRepo(store *Store) domain.WorkflowEventRepo {
+	return &memoryWorkflowEventRepo{store: store}
+}
+
+func (r *memoryWorkflowEventRepo) AppendEvents(ctx context.Context, orderID string, version int64, events []*domain.WorkflowEvent) error {
+	return r.store.do(ctx, func(d *data) error {
+		stream := d.events[orderID]
+		if int64(len(stream)) != version {
+			return &domain.VersionConflictError{OrderID: orderID, Version: version}
+		}
+		stream = slices.Clip(stream)
+		for i, e := range events {
+			e.OrderID = orderID
+			e.Sequence = version + int64(i) + 1
+			if e.CreatedAt.IsZero() {
+				e.CreatedAt = time.Now()
+			}
+			saved, err := copyEvent(e)
+			if err != nil {
+				return err
+			}
+			stream = append(stream, saved)
+		}
+		d.events[orderID] = stream
+		return nil
+	})
+}
+
+func (r *memoryWorkflowEventRepo) GetEvents(ctx context.Context, orderID string, after int64) ([]*domain.WorkflowEvent, error) {
+	var events []*domain.WorkflowEvent
+	err := r.store.do(ctx, func(d *data) error {
+		for _, stored := range d.events[orderID] {
+			if stored.Sequence <= after {
+				continue
+			}
+			e, err := copyEvent(stored)
+			if err != nil {
+				return err
+			}
+			events = append(events, e)
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (r *memoryWorkflowEventRepo) GetOrderIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := r.store.do(ctx, func(d *data) error {
+		ids = slices.Sorted(maps.Keys(d.events))
+		return nil
+	})
+	return ids, err
+}
+
+func (r *memoryWorkflowEventRepo) GetSnapshot(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := r.store.do(ctx, func(d *data) error {
+		stored, ok := d.snapshots[orderID]
+		if !ok {
+			return nil
+		}
+		var err error
+		state, err = copyState(stored)
+		return err
+	})
+	return state, err
+}
+
+func (r *memoryWorkflowEventRepo) SaveSnapshot(ctx context.Context, state *domain.WorkflowState) error {
+	return r.store.do(ctx, func(d *data) error {
+		saved, err := copyState(state)
+		if err != nil {
+			return err
+		}
+		d.snapshots[state.OrderID] = saved
+		return nil
+	})
+}
+
+// copyEvent returns a copy of e as it comes back from the events table.
+func copyEvent(e *domain.WorkflowEvent) (*domain.WorkflowEvent, error) {
+	c, err := copyJSON(e, "")
+	if err != nil {
+		return nil, err
+	}
+	c.OrderID, c.Sequence, c.Type, c.CreatedAt = e.OrderID, e.Sequence, e.Type, e.CreatedAt
+	return c, nil
+}
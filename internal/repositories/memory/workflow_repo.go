@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// runningStatuses are the statuses GetRunningVersions counts.
+var runningStatuses = []domain.WorkflowStatus{domain.StatusPending, domain.StatusWaiting, domain.StatusCompensating, domain.StatusCompensationFailed, domain.StatusPaused}
+
+type memoryWorkflowRepo struct {
+	store *Store
+}
+
+func NewWorkflowRepo(store *Store) domain.WorkflowRepo {
+	return &memoryWorkflowRepo{store: store}
+}
+
+func (r *memoryWorkflowRepo) SaveState(ctx context.Context, state *domain.WorkflowState) error {
+	return r.store.do(ctx, func(d *data) error {
+		saved, err := copyState(state)
+		if err != nil {
+			return err
+		}
+		saved.Version = 1
+		if existing, ok := d.workflows[state.OrderID]; ok {
+			if existing.Version != state.Version {
+				return &domain.VersionConflictError{OrderID: state.OrderID, Version: state.Version}
+			}
+			// like the upsert, what the workflow runs and its parent stay as they were
+			saved.RootOrderID = existing.RootOrderID
+			saved.Workflow = existing.Workflow
+			saved.WorkflowVersion = existing.WorkflowVersion
+			saved.ParentOrderID = existing.ParentOrderID
+			saved.ParentStep = existing.ParentStep
+			saved.CreatedAt = existing.CreatedAt
+			saved.Version = existing.Version + 1
+		}
+		saved.UpdatedAt = time.Now()
+		d.workflows[state.OrderID] = saved
+		state.Version = saved.Version
+		return nil
+	})
+}
+
+// saveProjection stores state as it is, version included, for an
+// event-sourced workflow whose stream is the source of truth.
+func (r *memoryWorkflowRepo) saveProjection(ctx context.Context, state *domain.WorkflowState) error {
+	return r.store.do(ctx, func(d *data) error {
+		saved, err := copyState(state)
+		if err != nil {
+			return err
+		}
+		d.workflows[state.OrderID] = saved
+		return nil
+	})
+}
+
+func (r *memoryWorkflowRepo) GetStateByOrderID(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := r.store.do(ctx, func(d *data) error {
+		stored, ok := d.workflows[orderID]
+		if !ok {
+			return nil
+		}
+		var err error
+		state, err = copyState(stored)
+		return err
+	})
+	return state, err
+}
+
+func (r *memoryWorkflowRepo) GetStalledWorkflows(ctx context.Context, timeout time.Duration) ([]*domain.WorkflowState, error) {
+	var states []*domain.WorkflowState
+	err := r.store.do(ctx, func(d *data) error {
+		before := time.Now().Add(-timeout)
+		for _, stored := range d.workflows {
+			if stored.Status != domain.StatusPending && stored.Status != domain.StatusCompensating {
+				continue
+			}
+			if !stored.UpdatedAt.Before(before) {
+				continue
+			}
+			state, err := copyState(stored)
+			if err != nil {
+				return err
+			}
+			states = append(states, state)
+		}
+		return nil
+	})
+	slices.SortFunc(states, func(a, b *domain.WorkflowState) int { return strings.Compare(a.OrderID, b.OrderID) })
+	return states, err
+}
+
+func (r *memoryWorkflowRepo) ListWorkflows(ctx context.Context, filter domain.WorkflowFilter) ([]*domain.WorkflowState, error) {
+	var states []*domain.WorkflowState
+	err := r.store.do(ctx, func(d *data) error {
+		for _, stored := range d.workflows {
+			if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, stored.Status) {
+				continue
+			}
+			if !filter.CreatedAfter.IsZero() && stored.CreatedAt.Before(filter.CreatedAfter) {
+				continue
+			}
+			if !filter.CreatedBefore.IsZero() && !stored.CreatedAt.Before(filter.CreatedBefore) {
+				continue
+			}
+			if c := filter.After; c != nil && compareListOrder(stored, c) <= 0 {
+				continue
+			}
+			state, err := copyState(stored)
+			if err != nil {
+				return err
+			}
+			states = append(states, state)
+		}
+		return nil
+	})
+	slices.SortFunc(states, func(a, b *domain.WorkflowState) int {
+		return compareListOrder(a, &domain.WorkflowCursor{CreatedAt: b.CreatedAt, OrderID: b.OrderID})
+	})
+	if filter.Limit > 0 && len(states) > filter.Limit {
+		states = states[:filter.Limit]
+	}
+	return states, err
+}
+
+// compareListOrder compares the position of state in the list order with c:
+// negative if state comes before it.
+func compareListOrder(state *domain.WorkflowState, c *domain.WorkflowCursor) int {
+	if cmp := c.CreatedAt.Compare(state.CreatedAt); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(c.OrderID, state.OrderID)
+}
+
+// update applies fn to a copy of the workflow of orderID and stores it as the
+// next version, unless fn reports that it changed nothing. It returns the
+// stored state, or nil if the workflow does not exist or fn changed nothing.
+func (r *memoryWorkflowRepo) update(ctx context.Context, orderID string, fn func(state *domain.WorkflowState) bool) (*domain.WorkflowState, error) {
+	var state *domain.WorkflowState
+	err := r.store.do(ctx, func(d *data) error {
+		stored, ok := d.workflows[orderID]
+		if !ok {
+			return nil
+		}
+		next, err := copyState(stored)
+		if err != nil {
+			return err
+		}
+		if !fn(next) {
+			return nil
+		}
+		next.Version++
+		next.UpdatedAt = time.Now()
+		d.workflows[orderID] = next
+		state, err = copyState(next)
+		return err
+	})
+	return state, err
+}
+
+func (r *memoryWorkflowRepo) CompleteStep(ctx context.Context, orderID string, step domain.Step, output map[string]any) (*domain.WorkflowState, error) {
+	data, err := copyJSON(output, "{}")
+	if err != nil {
+		return nil, err
+	}
+	return r.update(ctx, orderID, func(state *domain.WorkflowState) bool {
+		if !slices.Contains(state.ActiveSteps, step) {
+			return false
+		}
+		state.ActiveSteps = slices.DeleteFunc(state.ActiveSteps, func(s domain.Step) bool { return s == step })
+		state.CompletedSteps = append(state.CompletedSteps, step)
+		if state.StepOutputs == nil {
+			state.StepOutputs = make(map[domain.Step]map[string]any)
+		}
+		state.StepOutputs[step] = data
+		return true
+	})
+}
+
+func (r *memoryWorkflowRepo) AppendCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	state, err := r.update(ctx, orderID, func(state *domain.WorkflowState) bool {
+		state.PendingCompensations = append(state.PendingCompensations, step)
+		return true
+	})
+	if err == nil && state == nil {
+		return nil, fmt.Errorf("workflow not found for order %s", orderID)
+	}
+	return state, err
+}
+
+func (r *memoryWorkflowRepo) PopCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	return r.update(ctx, orderID, func(state *domain.WorkflowState) bool {
+		if len(state.PendingCompensations) == 0 || state.PendingCompensations[0] != step {
+			return false
+		}
+		state.PendingCompensations = state.PendingCompensations[1:]
+		return true
+	})
+}
+
+func (r *memoryWorkflowRepo) UpdateStatus(ctx context.Context, orderID string, from, to domain.WorkflowStatus) (bool, error) {
+	state, err := r.update(ctx, orderID, func(state *domain.WorkflowState) bool {
+		if state.Status != from {
+			return false
+		}
+		state.Status = to
+		return true
+	})
+	return state != nil, err
+}
+
+func (r *memoryWorkflowRepo) SetTimer(ctx context.Context, orderID string, step domain.Step, wakeAt time.Time) (time.Time, error) {
+	var stored time.Time
+	state, err := r.update(ctx, orderID, func(state *domain.WorkflowState) bool {
+		if state.Timers == nil {
+			state.Timers = make(map[domain.Step]time.Time)
+		}
+		if _, ok := state.Timers[step]; !ok {
+			state.Timers[step] = wakeAt.UTC()
+		}
+		stored = state.Timers[step]
+		return true
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if state == nil {
+		return time.Time{}, fmt.Errorf("workflow not found for order %s", orderID)
+	}
+	return stored, nil
+}
+
+func (r *memoryWorkflowRepo) GetRunningVersions(ctx context.Context) ([]*domain.WorkflowVersionUsage, error) {
+	var usages []*domain.WorkflowVersionUsage
+	err := r.store.do(ctx, func(d *data) error {
+		running := make(map[domain.WorkflowVersionUsage]int)
+		for _, state := range d.workflows {
+			if slices.Contains(runningStatuses, state.Status) {
+				running[domain.WorkflowVersionUsage{Workflow: state.Workflow, Version: state.WorkflowVersion}]++
+			}
+		}
+		for usage, n := range running {
+			usages = append(usages, &domain.WorkflowVersionUsage{Workflow: usage.Workflow, Version: usage.Version, Running: n})
+		}
+		return nil
+	})
+	slices.SortFunc(usages, func(a, b *domain.WorkflowVersionUsage) int {
+		if c := strings.Compare(a.Workflow, b.Workflow); c != 0 {
+			return c
+		}
+		return a.Version - b.Version
+	})
+	return usages, err
+}
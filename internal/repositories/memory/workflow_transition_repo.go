@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type memoryWorkflowTransitionRepo struct {
+	store *Store
+}
+
+func NewWorkflowTransitionRepo(store *Store) domain.WorkflowTransitionRepo {
+	return &memoryWorkflowTransitionRepo{store: store}
+}
+
+func (r *memoryWorkflowTransitionRepo) AddTransition(ctx context.Context, t *domain.WorkflowTransition) error {
+	return r.store.do(ctx, func(d *data) error {
+		d.lastTransitionID++
+		saved := *t
+		saved.ID = d.lastTransitionID
+		saved.CreatedAt = time.Now()
+		d.transitions = append(d.transitions, &saved)
+		t.ID, t.CreatedAt = saved.ID, saved.CreatedAt
+		return nil
+	})
+}
+
+func (r *memoryWorkflowTransitionRepo) GetTransitionsByOrderID(ctx context.Context, orderID string) ([]*domain.WorkflowTransition, error) {
+	return r.GetTransitionsAfter(ctx, orderID, 0)
+}
+
+func (r *memoryWorkflowTransitionRepo) GetTransitionsAfter(ctx context.Context, orderID string, afterID int64) ([]*domain.WorkflowTransition, error) {
+	var transitions []*domain.WorkflowTransition
+	err := r.store.do(ctx, func(d *data) error {
+		for _, stored := range d.transitions {
+			if stored.OrderID == orderID && stored.ID > afterID {
+				t := *stored
+				transitions = append(transitions, &t)
+			}
+		}
+		return nil
+	})
+	return transitions, err
+}
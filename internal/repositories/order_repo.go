@@ -18,14 +18,18 @@ func NewOrderRepo(db *sql.DB) domain.OrderRepo {
 }
 
 func (r *postgresOrderRepo) SaveOrder(ctx context.Context, order *domain.Order) error {
+	data, err := marshalJSON(order.Data, "{}")
+	if err != nil {
+		return err
+	}
 	query := `
-		INSERT INTO orders (id, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO orders (id, status, data, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (id) DO UPDATE SET
 			status = EXCLUDED.status,
 			updated_at = EXCLUDED.updated_at
 	`
-	_, err := r.db.ExecContext(ctx, query, order.ID, order.Status, order.CreatedAt, time.Now())
+	_, err = dbFrom(ctx, r.db).ExecContext(ctx, query, order.ID, order.Status, data, order.CreatedAt, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to save order: %w", err)
 	}
@@ -33,14 +37,18 @@ func (r *postgresOrderRepo) SaveOrder(ctx context.Context, order *domain.Order)
 }
 
 func (r *postgresOrderRepo) GetOrderByID(ctx context.Context, orderID string) (*domain.Order, error) {
-	query := `SELECT id, status, created_at, updated_at FROM orders WHERE id = $1`
+	query := `SELECT id, status, data, created_at, updated_at FROM orders WHERE id = $1`
 	order := &domain.Order{}
-	err := r.db.QueryRowContext(ctx, query, orderID).Scan(&order.ID, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+	var data []byte
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, orderID).Scan(&order.ID, &order.Status, &data, &order.CreatedAt, &order.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil // Not found, return nil order
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order by ID %s: %w", orderID, err)
 	}
+	if err := unmarshalJSON(data, &order.Data); err != nil {
+		return nil, err
+	}
 	return order, nil
 }
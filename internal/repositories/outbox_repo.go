@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type postgresOutboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) domain.OutboxRepo {
+	return &postgresOutboxRepo{db: db}
+}
+
+func (r *postgresOutboxRepo) AddMessage(ctx context.Context, msg *domain.OutboxMessage) error {
+	var processAt sql.NullTime
+	if !msg.ProcessAt.IsZero() {
+		processAt = sql.NullTime{Time: msg.ProcessAt, Valid: true}
+	}
+	query := `
+		INSERT INTO outbox (task_type, payload, task_id, process_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, msg.TaskType, msg.Payload, nullString(msg.TaskID), processAt).Scan(&msg.ID, &msg.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add outbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOutboxRepo) GetUnsentMessages(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	query := `
+		SELECT id, task_type, payload, task_id, process_at, created_at
+		FROM outbox
+		WHERE sent_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var msgs []*domain.OutboxMessage
+	for rows.Next() {
+		msg := &domain.OutboxMessage{}
+		var (
+			taskID    sql.NullString
+			processAt sql.NullTime
+		)
+		if err := rows.Scan(&msg.ID, &msg.TaskType, &msg.Payload, &taskID, &processAt, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		msg.TaskID = taskID.String
+		msg.ProcessAt = processAt.Time
+		msgs = append(msgs, msg)
+	}
+	return msgs, rows.Err()
+}
+
+func (r *postgresOutboxRepo) MarkSent(ctx context.Context, ids []int64) error {
+	query := `UPDATE outbox SET sent_at = $2 WHERE id = ANY($1)`
+	if _, err := dbFrom(ctx, r.db).ExecContext(ctx, query, pq.Array(ids), time.Now()); err != nil {
+		return fmt.Errorf("failed to mark outbox messages sent: %w", err)
+	}
+	return nil
+}
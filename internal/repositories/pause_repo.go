@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type postgresPauseRepo struct {
+	db *sql.DB
+}
+
+func NewPauseRepo(db *sql.DB) domain.PauseRepo {
+	return &postgresPauseRepo{db: db}
+}
+
+func (r *postgresPauseRepo) AddPause(ctx context.Context, p *domain.WorkflowPause) error {
+	query := `
+		INSERT INTO workflow_pauses (step, reason)
+		VALUES ($1, $2)
+		ON CONFLICT (step) DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING created_at
+	`
+	if err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, p.Step, p.Reason).Scan(&p.CreatedAt); err != nil {
+		return fmt.Errorf("failed to add pause of step %q: %w", p.Step, err)
+	}
+	return nil
+}
+
+func (r *postgresPauseRepo) RemovePause(ctx context.Context, step domain.Step) (bool, error) {
+	res, err := dbFrom(ctx, r.db).ExecContext(ctx, `DELETE FROM workflow_pauses WHERE step = $1`, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove pause of step %q: %w", step, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove pause of step %q: %w", step, err)
+	}
+	return n > 0, nil
+}
+
+func (r *postgresPauseRepo) GetPauses(ctx context.Context) ([]*domain.WorkflowPause, error) {
+	query := `SELECT step, reason, created_at FROM workflow_pauses ORDER BY created_at, step`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []*domain.WorkflowPause
+	for rows.Next() {
+		p := &domain.WorkflowPause{}
+		if err := rows.Scan(&p.Step, &p.Reason, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pause: %w", err)
+		}
+		pauses = append(pauses, p)
+	}
+	return pauses, rows.Err()
+}
@@ -16,24 +16,40 @@ func NewStepExecutionRepo(db *sql.DB) domain.StepExecutionRepo {
 	return &postgresStepExecutionRepo{db: db}
 }
 
-func (r *postgresStepExecutionRepo) IsExecuted(ctx context.Context, dedupeKey string) (bool, string, error) {
-	var result string
-	query := `SELECT result FROM step_executions WHERE dedupe_key = $1`
-	err := r.db.QueryRowContext(ctx, query, dedupeKey).Scan(&result)
+func (r *postgresStepExecutionRepo) GetExecution(ctx context.Context, dedupeKey string) (*domain.StepExecution, error) {
+	exec := &domain.StepExecution{}
+	var output []byte
+	query := `SELECT dedupe_key, result, output, executed_at FROM step_executions WHERE dedupe_key = $1`
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, dedupeKey).Scan(&exec.DedupeKey, &exec.Result, &output, &exec.ExecutedAt)
 	if err == sql.ErrNoRows {
-		return false, "", nil
+		return nil, nil
 	}
 	if err != nil {
-		return false, "", fmt.Errorf("failed to check execution: %w", err)
+		return nil, fmt.Errorf("failed to check execution: %w", err)
 	}
-	return true, result, nil
+	if err := unmarshalJSON(output, &exec.Output); err != nil {
+		return nil, err
+	}
+	return exec, nil
 }
 
-func (r *postgresStepExecutionRepo) SaveExecution(ctx context.Context, dedupeKey, result string) error {
-	query := `INSERT INTO step_executions (dedupe_key, result) VALUES ($1, $2)`
-	_, err := r.db.ExecContext(ctx, query, dedupeKey, result)
+func (r *postgresStepExecutionRepo) SaveExecution(ctx context.Context, exec *domain.StepExecution) error {
+	output, err := marshalJSON(exec.Output, "{}")
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO step_executions (dedupe_key, result, output) VALUES ($1, $2, $3)`
+	_, err = dbFrom(ctx, r.db).ExecContext(ctx, query, exec.DedupeKey, exec.Result, output)
 	if err != nil {
 		return fmt.Errorf("failed to save execution: %w", err)
 	}
 	return nil
 }
+
+func (r *postgresStepExecutionRepo) DeleteExecution(ctx context.Context, dedupeKey string) error {
+	query := `DELETE FROM step_executions WHERE dedupe_key = $1`
+	if _, err := dbFrom(ctx, r.db).ExecContext(ctx, query, dedupeKey); err != nil {
+		return fmt.Errorf("failed to delete execution: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type postgresWorkflowEventRepo struct {
+	db *sql.DB
+}
+
+func NewWorkflowEventRepo(db *sql.DB) domain.WorkflowEventRepo {
+	return &postgresWorkflowEventRepo{db: db}
+}
+
+func (r *postgresWorkflowEventRepo) AppendEvents(ctx context.Context, orderID string, version int64, events []*domain.WorkflowEvent) error {
+	query := `
+		INSERT INTO workflow_events (order_id, sequence, type, data, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	db := dbFrom(ctx, r.db)
+	var current int64
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM workflow_events WHERE order_id = $1`, orderID).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to get version of order %s: %w", orderID, err)
+	}
+	if current != version {
+		return &domain.VersionConflictError{OrderID: orderID, Version: version}
+	}
+	// a concurrent append of the same sequence fails on the primary key
+	for i, e := range events {
+		e.OrderID = orderID
+		e.Sequence = version + int64(i) + 1
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = time.Now()
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", e.Type, err)
+		}
+		_, err = db.ExecContext(ctx, query, orderID, e.Sequence, e.Type, data, e.CreatedAt)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return &domain.VersionConflictError{OrderID: orderID, Version: version}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to append %s event for order %s: %w", e.Type, orderID, err)
+		}
+	}
+	return nil
+}
+
+func (r *postgresWorkflowEventRepo) GetEvents(ctx context.Context, orderID string, after int64) ([]*domain.WorkflowEvent, error) {
+	query := `
+		SELECT order_id, sequence, type, data, created_at
+		FROM workflow_events
+		WHERE order_id = $1 AND sequence > $2
+		ORDER BY sequence
+	`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, orderID, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.WorkflowEvent
+	for rows.Next() {
+		e := &domain.WorkflowEvent{}
+		var (
+			orderID, eventType string
+			sequence           int64
+			data               []byte
+			createdAt          time.Time
+		)
+		if err := rows.Scan(&orderID, &sequence, &eventType, &data, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		if err := unmarshalJSON(data, e); err != nil {
+			return nil, err
+		}
+		e.OrderID, e.Sequence, e.Type, e.CreatedAt = orderID, sequence, domain.EventType(eventType), createdAt
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *postgresWorkflowEventRepo) GetOrderIDs(ctx context.Context) ([]string, error) {
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, `SELECT DISTINCT order_id FROM workflow_events ORDER BY order_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event streams: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan event stream: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *postgresWorkflowEventRepo) GetSnapshot(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	var data []byte
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, `SELECT state FROM workflow_snapshots WHERE order_id = $1`, orderID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	state := &domain.WorkflowState{}
+	if err := unmarshalJSON(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (r *postgresWorkflowEventRepo) SaveSnapshot(ctx context.Context, state *domain.WorkflowState) error {
+	data, err := marshalJSON(state, "")
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO workflow_snapshots (order_id, sequence, state, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (order_id) DO UPDATE SET
+			sequence = EXCLUDED.sequence,
+			state = EXCLUDED.state,
+			created_at = EXCLUDED.created_at
+	`
+	if _, err := dbFrom(ctx, r.db).ExecContext(ctx, query, state.OrderID, state.Version, data, time.Now()); err != nil {
+		return fmt.Errorf("failed to save snapshot of order %s: %w", state.OrderID, err)
+	}
+	return nil
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
 )
 
+const workflowColumns = `order_id, root_order_id, workflow_name, workflow_version, parent_order_id, parent_step, current_step, active_steps, completed_steps, pending_compensations, step_outputs, branches, timers, compensation_failure, paused_by, held_steps, retry_from, status, version, created_at, updated_at`
+
 type postgresWorkflowRepo struct {
 	db *sql.DB
 }
@@ -18,25 +22,110 @@ func NewWorkflowRepo(db *sql.DB) domain.WorkflowRepo {
 }
 
 func (r *postgresWorkflowRepo) SaveState(ctx context.Context, state *domain.WorkflowState) error {
+	args, err := stateArgs(state, time.Now())
+	if err != nil {
+		return err
+	}
 	query := `
-		INSERT INTO workflows (order_id, current_step, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO workflows (order_id, root_order_id, workflow_name, workflow_version, parent_order_id, parent_step, current_step, active_steps, completed_steps, pending_compensations, step_outputs, branches, timers, compensation_failure, paused_by, held_steps, retry_from, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		ON CONFLICT (order_id) DO UPDATE SET
 			current_step = EXCLUDED.current_step,
+			active_steps = EXCLUDED.active_steps,
+			completed_steps = EXCLUDED.completed_steps,
+			pending_compensations = EXCLUDED.pending_compensations,
+			step_outputs = EXCLUDED.step_outputs,
+			branches = EXCLUDED.branches,
+			timers = EXCLUDED.timers,
+			compensation_failure = EXCLUDED.compensation_failure,
+			paused_by = EXCLUDED.paused_by,
+			held_steps = EXCLUDED.held_steps,
+			retry_from = EXCLUDED.retry_from,
 			status = EXCLUDED.status,
-			updated_at = EXCLUDED.updated_at
+			updated_at = EXCLUDED.updated_at,
+			version = workflows.version + 1
+		WHERE workflows.version = $21
+		RETURNING version
 	`
-	_, err := r.db.ExecContext(ctx, query, state.OrderID, state.CurrentStep, state.Status, state.CreatedAt, time.Now())
+	err = dbFrom(ctx, r.db).QueryRowContext(ctx, query, append(args, state.Version)...).Scan(&state.Version)
+	if err == sql.ErrNoRows {
+		return &domain.VersionConflictError{OrderID: state.OrderID, Version: state.Version}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save workflow state: %w", err)
 	}
 	return nil
 }
 
+// saveProjection writes state to the workflows table as it is, version
+// included, for an event-sourced workflow whose stream is the source of truth.
+func (r *postgresWorkflowRepo) saveProjection(ctx context.Context, state *domain.WorkflowState) error {
+	args, err := stateArgs(state, state.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO workflows (order_id, root_order_id, workflow_name, workflow_version, parent_order_id, parent_step, current_step, active_steps, completed_steps, pending_compensations, step_outputs, branches, timers, compensation_failure, paused_by, held_steps, retry_from, status, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		ON CONFLICT (order_id) DO UPDATE SET
+			root_order_id = EXCLUDED.root_order_id,
+			workflow_name = EXCLUDED.workflow_name,
+			workflow_version = EXCLUDED.workflow_version,
+			parent_order_id = EXCLUDED.parent_order_id,
+			parent_step = EXCLUDED.parent_step,
+			current_step = EXCLUDED.current_step,
+			active_steps = EXCLUDED.active_steps,
+			completed_steps = EXCLUDED.completed_steps,
+			pending_compensations = EXCLUDED.pending_compensations,
+			step_outputs = EXCLUDED.step_outputs,
+			branches = EXCLUDED.branches,
+			timers = EXCLUDED.timers,
+			compensation_failure = EXCLUDED.compensation_failure,
+			paused_by = EXCLUDED.paused_by,
+			held_steps = EXCLUDED.held_steps,
+			retry_from = EXCLUDED.retry_from,
+			status = EXCLUDED.status,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at,
+			version = EXCLUDED.version
+	`
+	if _, err := dbFrom(ctx, r.db).ExecContext(ctx, query, append(args, state.Version)...); err != nil {
+		return fmt.Errorf("failed to save workflow projection of order %s: %w", state.OrderID, err)
+	}
+	return nil
+}
+
+// stateArgs returns the values of the workflows columns of state, in the
+// order of SaveState, stamped with updatedAt.
+func stateArgs(state *domain.WorkflowState, updatedAt time.Time) ([]any, error) {
+	outputs, err := marshalJSON(state.StepOutputs, "{}")
+	if err != nil {
+		return nil, err
+	}
+	branches, err := marshalJSON(state.Branches, "[]")
+	if err != nil {
+		return nil, err
+	}
+	timers, err := marshalJSON(state.Timers, "{}")
+	if err != nil {
+		return nil, err
+	}
+	var failure []byte
+	if state.CompensationFailure != nil {
+		if failure, err = marshalJSON(state.CompensationFailure, ""); err != nil {
+			return nil, err
+		}
+	}
+	return []any{
+		state.OrderID, state.RootOrderID, state.Workflow, state.WorkflowVersion, nullString(state.ParentOrderID), nullString(string(state.ParentStep)),
+		state.CurrentStep, pq.Array(stepStrings(state.ActiveSteps)), pq.Array(stepStrings(state.CompletedSteps)), pq.Array(stepStrings(state.PendingCompensations)),
+		outputs, branches, timers, nullString(string(failure)), state.PausedBy, pq.Array(stepStrings(state.HeldSteps)), state.RetryFrom, state.Status, state.CreatedAt, updatedAt,
+	}, nil
+}
+
 func (r *postgresWorkflowRepo) GetStateByOrderID(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
-	state := &domain.WorkflowState{}
-	query := `SELECT order_id, current_step, status, created_at, updated_at FROM workflows WHERE order_id = $1`
-	err := r.db.QueryRowContext(ctx, query, orderID).Scan(&state.OrderID, &state.CurrentStep, &state.Status, &state.CreatedAt, &state.UpdatedAt)
+	query := `SELECT ` + workflowColumns + ` FROM workflows WHERE order_id = $1`
+	state, err := scanWorkflowState(dbFrom(ctx, r.db).QueryRowContext(ctx, query, orderID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -47,8 +136,8 @@ func (r *postgresWorkflowRepo) GetStateByOrderID(ctx context.Context, orderID st
 }
 
 func (r *postgresWorkflowRepo) GetStalledWorkflows(ctx context.Context, timeout time.Duration) ([]*domain.WorkflowState, error) {
-	query := `SELECT order_id, current_step, status, created_at, updated_at FROM workflows WHERE status = 'pending' AND updated_at < $1`
-	rows, err := r.db.QueryContext(ctx, query, time.Now().Add(-timeout))
+	query := `SELECT ` + workflowColumns + ` FROM workflows WHERE status IN ('pending', 'compensating') AND updated_at < $1`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, time.Now().Add(-timeout))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query stalled workflows: %w", err)
 	}
@@ -56,11 +145,237 @@ func (r *postgresWorkflowRepo) GetStalledWorkflows(ctx context.Context, timeout
 
 	var states []*domain.WorkflowState
 	for rows.Next() {
-		state := &domain.WorkflowState{}
-		if err := rows.Scan(&state.OrderID, &state.CurrentStep, &state.Status, &state.CreatedAt, &state.UpdatedAt); err != nil {
+		state, err := scanWorkflowState(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan stalled workflow: %w", err)
 		}
 		states = append(states, state)
 	}
 	return states, rows.Err()
 }
+
+func (r *postgresWorkflowRepo) CompleteStep(ctx context.Context, orderID string, step domain.Step, output map[string]any) (*domain.WorkflowState, error) {
+	data, err := marshalJSON(output, "{}")
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE workflows SET
+			active_steps = array_remove(active_steps, $2),
+			completed_steps = array_append(completed_steps, $2),
+			step_outputs = step_outputs || jsonb_build_object($2::text, $3::jsonb),
+			updated_at = $4,
+			version = version + 1
+		WHERE order_id = $1 AND $2 = ANY(active_steps)
+		RETURNING ` + workflowColumns
+	state, err := scanWorkflowState(dbFrom(ctx, r.db).QueryRowContext(ctx, query, orderID, string(step), data, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete step %s for order %s: %w", step, orderID, err)
+	}
+	return state, nil
+}
+
+func (r *postgresWorkflowRepo) AppendCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	query := `
+		UPDATE workflows SET
+			pending_compensations = array_append(pending_compensations, $2),
+			updated_at = $3,
+			version = version + 1
+		WHERE order_id = $1
+		RETURNING ` + workflowColumns
+	state, err := scanWorkflowState(dbFrom(ctx, r.db).QueryRowContext(ctx, query, orderID, string(step), time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("workflow not found for order %s", orderID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add compensation of %s for order %s: %w", step, orderID, err)
+	}
+	return state, nil
+}
+
+func (r *postgresWorkflowRepo) PopCompensation(ctx context.Context, orderID string, step domain.Step) (*domain.WorkflowState, error) {
+	query := `
+		UPDATE workflows SET
+			pending_compensations = pending_compensations[2:],
+			updated_at = $3,
+			version = version + 1
+		WHERE order_id = $1 AND pending_compensations[1] = $2
+		RETURNING ` + workflowColumns
+	state, err := scanWorkflowState(dbFrom(ctx, r.db).QueryRowContext(ctx, query, orderID, string(step), time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pop compensation of %s for order %s: %w", step, orderID, err)
+	}
+	return state, nil
+}
+
+func (r *postgresWorkflowRepo) UpdateStatus(ctx context.Context, orderID string, from, to domain.WorkflowStatus) (bool, error) {
+	query := `UPDATE workflows SET status = $3, updated_at = $4, version = version + 1 WHERE order_id = $1 AND status = $2`
+	res, err := dbFrom(ctx, r.db).ExecContext(ctx, query, orderID, from, to, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to update status of workflow %s: %w", orderID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update status of workflow %s: %w", orderID, err)
+	}
+	return n == 1, nil
+}
+
+func (r *postgresWorkflowRepo) SetTimer(ctx context.Context, orderID string, step domain.Step, wakeAt time.Time) (time.Time, error) {
+	query := `
+		UPDATE workflows SET
+			timers = jsonb_build_object($2::text, $3::text) || timers,
+			updated_at = $4,
+			version = version + 1
+		WHERE order_id = $1
+		RETURNING timers->>$2
+	`
+	var stored string
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, orderID, string(step), wakeAt.UTC().Format(time.RFC3339Nano), time.Now()).Scan(&stored)
+	if err == sql.ErrNoRows {
+		return time.Time{}, fmt.Errorf("workflow not found for order %s", orderID)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to set timer %s for order %s: %w", step, orderID, err)
+	}
+	return time.Parse(time.RFC3339Nano, stored)
+}
+
+func (r *postgresWorkflowRepo) GetRunningVersions(ctx context.Context) ([]*domain.WorkflowVersionUsage, error) {
+	query := `
+		SELECT workflow_name, workflow_version, COUNT(*)
+		FROM workflows
+		WHERE status IN ('pending', 'waiting', 'compensating', 'compensation_failed', 'paused')
+		GROUP BY workflow_name, workflow_version
+		ORDER BY workflow_name, workflow_version
+	`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query running workflow versions: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []*domain.WorkflowVersionUsage
+	for rows.Next() {
+		usage := &domain.WorkflowVersionUsage{}
+		if err := rows.Scan(&usage.Workflow, &usage.Version, &usage.Running); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow version: %w", err)
+		}
+		usages = append(usages, usage)
+	}
+	return usages, rows.Err()
+}
+
+func (r *postgresWorkflowRepo) ListWorkflows(ctx context.Context, filter domain.WorkflowFilter) ([]*domain.WorkflowState, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, s := range filter.Statuses {
+			statuses[i] = string(s)
+		}
+		conds = append(conds, "status = ANY("+arg(pq.Array(statuses))+")")
+	}
+	if !filter.CreatedAfter.IsZero() {
+		conds = append(conds, "created_at >= "+arg(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		conds = append(conds, "created_at < "+arg(filter.CreatedBefore))
+	}
+	if c := filter.After; c != nil {
+		conds = append(conds, fmt.Sprintf("(created_at, order_id) < (%s, %s)", arg(c.CreatedAt), arg(c.OrderID)))
+	}
+	query := `SELECT ` + workflowColumns + ` FROM workflows`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, order_id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + arg(filter.Limit)
+	}
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*domain.WorkflowState
+	for rows.Next() {
+		state, err := scanWorkflowState(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workflow: %w", err)
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWorkflowState(row rowScanner) (*domain.WorkflowState, error) {
+	state := &domain.WorkflowState{}
+	var (
+		parentOrderID, parentStep          sql.NullString
+		active, completed, pending, held   []string
+		outputs, branches, timers, failure []byte
+	)
+	err := row.Scan(&state.OrderID, &state.RootOrderID, &state.Workflow, &state.WorkflowVersion, &parentOrderID, &parentStep, &state.CurrentStep, pq.Array(&active), pq.Array(&completed), pq.Array(&pending),
+		&outputs, &branches, &timers, &failure, &state.PausedBy, pq.Array(&held), &state.RetryFrom, &state.Status, &state.Version, &state.CreatedAt, &state.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	state.ParentOrderID = parentOrderID.String
+	state.ParentStep = domain.Step(parentStep.String)
+	state.ActiveSteps = toSteps(active)
+	state.CompletedSteps = toSteps(completed)
+	state.PendingCompensations = toSteps(pending)
+	state.HeldSteps = toSteps(held)
+	if err := unmarshalJSON(outputs, &state.StepOutputs); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(branches, &state.Branches); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(timers, &state.Timers); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(failure, &state.CompensationFailure); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func stepStrings(steps []domain.Step) []string {
+	out := make([]string, len(steps))
+	for i, s := range steps {
+		out[i] = string(s)
+	}
+	return out
+}
+
+func toSteps(values []string) []domain.Step {
+	steps := make([]domain.Step, len(values))
+	for i, v := range values {
+		steps[i] = domain.Step(v)
+	}
+	return steps
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
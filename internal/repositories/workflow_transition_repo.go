@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+type postgresWorkflowTransitionRepo struct {
+	db *sql.DB
+}
+
+func NewWorkflowTransitionRepo(db *sql.DB) domain.WorkflowTransitionRepo {
+	return &postgresWorkflowTransitionRepo{db: db}
+}
+
+func (r *postgresWorkflowTransitionRepo) AddTransition(ctx context.Context, t *domain.WorkflowTransition) error {
+	query := `
+		INSERT INTO workflow_transitions (order_id, from_step, to_step, from_status, to_status, cause, error, worker)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, t.OrderID, nullString(string(t.FromStep)), nullString(string(t.ToStep)),
+		nullString(string(t.FromStatus)), t.ToStatus, t.Cause, nullString(t.Error), t.Worker).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add transition for order %s: %w", t.OrderID, err)
+	}
+	return nil
+}
+
+func (r *postgresWorkflowTransitionRepo) GetTransitionsByOrderID(ctx context.Context, orderID string) ([]*domain.WorkflowTransition, error) {
+	return r.GetTransitionsAfter(ctx, orderID, 0)
+}
+
+func (r *postgresWorkflowTransitionRepo) GetTransitionsAfter(ctx context.Context, orderID string, afterID int64) ([]*domain.WorkflowTransition, error) {
+	query := `
+		SELECT id, order_id, from_step, to_step, from_status, to_status, cause, error, worker, created_at
+		FROM workflow_transitions
+		WHERE order_id = $1 AND id > $2
+		ORDER BY id
+	`
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, orderID, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transitions: %w", err)
+	}
+	defer rows.Close()
+
+	var transitions []*domain.WorkflowTransition
+	for rows.Next() {
+		t := &domain.WorkflowTransition{}
+		var fromStep, toStep, fromStatus, stepErr sql.NullString
+		if err := rows.Scan(&t.ID, &t.OrderID, &fromStep, &toStep, &fromStatus, &t.ToStatus, &t.Cause, &stepErr, &t.Worker, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transition: %w", err)
+		}
+		t.FromStep = domain.Step(fromStep.String)
+		t.ToStep = domain.Step(toStep.String)
+		t.FromStatus = domain.WorkflowStatus(fromStatus.String)
+		t.Error = stepErr.String
+		transitions = append(transitions, t)
+	}
+	return transitions, rows.Err()
+}
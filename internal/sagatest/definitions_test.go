@@ -0,0 +1,291 @@
+package sagatest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/sagatest"
+)
+
+// run starts the first of defs for order-1 on a new engine, after setup, and
+// drains it.
+func run(t *testing.T, setup func(e *sagatest.Engine), defs ...*domain.WorkflowDefinition) *sagatest.Engine {
+	t.Helper()
+	ctx := context.Background()
+	e := sagatest.New()
+	if err := e.UseDefinitions(defs...); err != nil {
+		t.Fatal(err)
+	}
+	if setup != nil {
+		setup(e)
+	}
+	if err := e.Start(ctx, "order-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	drain(t, e)
+	return e
+}
+
+func drain(t *testing.T, e *sagatest.Engine) {
+	t.Helper()
+	if _, err := e.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkExecuted(t *testing.T, e *sagatest.Engine, orderID string, want []string) {
+	t.Helper()
+	if got := e.Executed(orderID); !slices.Equal(got, want) {
+		t.Errorf("executed %q, want %q", got, want)
+	}
+}
+
+func checkStatus(t *testing.T, e *sagatest.Engine, orderID string, want domain.WorkflowStatus) *domain.WorkflowState {
+	t.Helper()
+	state, err := e.State(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil {
+		t.Fatalf("workflow %s does not exist", orderID)
+	}
+	if state.Status != want {
+		t.Errorf("workflow %s is %s, want %s", orderID, state.Status, want)
+	}
+	return state
+}
+
+func TestParallelGroup(t *testing.T) {
+	def := &domain.WorkflowDefinition{
+		Name:    "parallel",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare", Compensation: "unprepare"},
+			{Name: "fan_out", Parallel: []domain.StepDefinition{
+				{Name: "pack", Compensation: "unpack"},
+				{Name: "label", Compensation: "unlabel"},
+			}},
+			{Name: "ship"},
+		},
+	}
+
+	t.Run("every branch runs before the next step", func(t *testing.T) {
+		e := run(t, nil, def)
+		checkExecuted(t, e, "order-1", []string{"step prepare", "step pack", "step label", "step ship"})
+		checkStatus(t, e, "order-1", domain.StatusCompleted)
+	})
+
+	t.Run("a failed branch compensates the branch that finished", func(t *testing.T) {
+		e := run(t, func(e *sagatest.Engine) {
+			e.FailStep("label", 1, domain.Terminal(errors.New("printer jammed")))
+		}, def)
+		checkExecuted(t, e, "order-1", []string{
+			"step prepare",
+			"step pack",
+			"step label failed: printer jammed",
+			"compensation unpack",
+			"compensation unprepare",
+		})
+		checkStatus(t, e, "order-1", domain.StatusCompensated)
+	})
+}
+
+func TestTimer(t *testing.T) {
+	ctx := context.Background()
+	def := &domain.WorkflowDefinition{
+		Name:    "timer",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare"},
+			{Name: "cool_down", Timer: &domain.TimerDefinition{Delay: domain.Duration(time.Hour)}},
+			{Name: "ship"},
+		},
+	}
+	e := run(t, nil, def)
+	checkExecuted(t, e, "order-1", []string{"step prepare"})
+	state := checkStatus(t, e, "order-1", domain.StatusPending)
+	if _, ok := state.Timers["cool_down"]; !ok {
+		t.Errorf("timer of cool_down is not set, timers are %v", state.Timers)
+	}
+
+	if _, err := e.Advance(ctx, 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	checkExecuted(t, e, "order-1", []string{"step prepare"})
+
+	if _, err := e.Advance(ctx, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	checkExecuted(t, e, "order-1", []string{"step prepare", "step ship"})
+	checkStatus(t, e, "order-1", domain.StatusCompleted)
+}
+
+func TestSignal(t *testing.T) {
+	ctx := context.Background()
+	def := &domain.WorkflowDefinition{
+		Name:    "signal",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare", Compensation: "unprepare"},
+			{Name: "approval", Signal: &domain.SignalDefinition{Name: "approved", Timeout: domain.Duration(time.Hour)}},
+			{Name: "ship"},
+		},
+	}
+
+	t.Run("the signal resumes the workflow with its payload", func(t *testing.T) {
+		e := run(t, nil, def)
+		checkStatus(t, e, "order-1", domain.StatusWaiting)
+		if err := e.Orchestrator.SignalWorkflow(ctx, "order-1", "shipped", nil); !errors.Is(err, domain.ErrSignalNotExpected) {
+			t.Errorf("unexpected signal returned %v, want %v", err, domain.ErrSignalNotExpected)
+		}
+		if err := e.Orchestrator.SignalWorkflow(ctx, "order-1", "approved", map[string]any{"by": "ops"}); err != nil {
+			t.Fatal(err)
+		}
+		drain(t, e)
+		checkExecuted(t, e, "order-1", []string{"step prepare", "step ship"})
+		state := checkStatus(t, e, "order-1", domain.StatusCompleted)
+		payload, _ := state.StepOutputs["approval"]["payload"].(map[string]any)
+		if payload["by"] != "ops" {
+			t.Errorf("output of approval is %v, want the payload of the signal", state.StepOutputs["approval"])
+		}
+	})
+
+	t.Run("no signal in time compensates the workflow", func(t *testing.T) {
+		e := run(t, nil, def)
+		if _, err := e.Advance(ctx, 2*time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		checkExecuted(t, e, "order-1", []string{"step prepare", "compensation unprepare"})
+		checkStatus(t, e, "order-1", domain.StatusCompensated)
+	})
+}
+
+func TestChildWorkflow(t *testing.T) {
+	parent := &domain.WorkflowDefinition{
+		Name:    "parent",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare", Compensation: "unprepare"},
+			{Name: "shipping", Workflow: "shipping"},
+			{Name: "notify"},
+		},
+	}
+	child := &domain.WorkflowDefinition{
+		Name:    "shipping",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "book_courier", Compensation: "cancel_courier"},
+			{Name: "print_label"},
+		},
+	}
+	childID := domain.ChildWorkflowID("order-1", "shipping")
+
+	t.Run("the child runs for the order of its parent", func(t *testing.T) {
+		e := run(t, nil, parent, child)
+		checkExecuted(t, e, "order-1", []string{"step prepare", "step book_courier", "step print_label", "step notify"})
+		checkExecuted(t, e, childID, nil)
+		checkStatus(t, e, "order-1", domain.StatusCompleted)
+		state := checkStatus(t, e, childID, domain.StatusCompleted)
+		if state.RootOrderID != "order-1" || state.ParentOrderID != "order-1" {
+			t.Errorf("child runs for order %s under %s, want order-1 under order-1", state.RootOrderID, state.ParentOrderID)
+		}
+		order, err := e.Orchestrator.GetWorkflow(context.Background(), childID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order.Order == nil || order.Order.ID != "order-1" {
+			t.Errorf("child workflow reports order %+v, want order-1", order.Order)
+		}
+	})
+
+	t.Run("a failure after the child compensates the child", func(t *testing.T) {
+		e := run(t, func(e *sagatest.Engine) {
+			e.FailStep("notify", 1, domain.Terminal(errors.New("no such customer")))
+		}, parent, child)
+		checkExecuted(t, e, "order-1", []string{
+			"step prepare",
+			"step book_courier",
+			"step print_label",
+			"step notify failed: no such customer",
+			"compensation cancel_courier",
+			"compensation unprepare",
+		})
+		checkStatus(t, e, "order-1", domain.StatusCompensated)
+		checkStatus(t, e, childID, domain.StatusCompensated)
+	})
+}
+
+func TestCancelWorkflow(t *testing.T) {
+	ctx := context.Background()
+	def := &domain.WorkflowDefinition{
+		Name:    "cancel",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare", Compensation: "unprepare"},
+			{Name: "approval", Signal: &domain.SignalDefinition{Name: "approved"}},
+			{Name: "ship"},
+		},
+	}
+	e := run(t, nil, def)
+	if err := e.Orchestrator.CancelWorkflow(ctx, "order-1", "customer changed their mind"); err != nil {
+		t.Fatal(err)
+	}
+	drain(t, e)
+	checkExecuted(t, e, "order-1", []string{"step prepare", "compensation unprepare"})
+	checkStatus(t, e, "order-1", domain.StatusCancelled)
+	if err := e.Orchestrator.SignalWorkflow(ctx, "order-1", "approved", nil); err == nil {
+		t.Errorf("signalling a cancelled workflow succeeded")
+	}
+}
+
+func TestCancelWorkflowWithRunningChild(t *testing.T) {
+	ctx := context.Background()
+	parent := &domain.WorkflowDefinition{
+		Name:    "parent",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare", Compensation: "unprepare"},
+			{Name: "shipping", Workflow: "shipping"},
+		},
+	}
+	child := &domain.WorkflowDefinition{
+		Name:    "shipping",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "book", Compensation: "cancel_booking"},
+			{Name: "courier", Workflow: "courier"},
+		},
+	}
+	grandchild := &domain.WorkflowDefinition{
+		Name:    "courier",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "dispatch", Compensation: "recall"},
+			{Name: "approval", Signal: &domain.SignalDefinition{Name: "approved"}},
+		},
+	}
+	childID := domain.ChildWorkflowID("order-1", "shipping")
+	grandchildID := domain.ChildWorkflowID(childID, "courier")
+
+	e := run(t, nil, parent, child, grandchild)
+	checkStatus(t, e, grandchildID, domain.StatusWaiting)
+	if err := e.Orchestrator.CancelWorkflow(ctx, "order-1", "customer changed their mind"); err != nil {
+		t.Fatal(err)
+	}
+	drain(t, e)
+	checkExecuted(t, e, "order-1", []string{
+		"step prepare",
+		"step book",
+		"step dispatch",
+		"compensation recall",
+		"compensation cancel_booking",
+		"compensation unprepare",
+	})
+	for _, orderID := range []string{"order-1", childID, grandchildID} {
+		checkStatus(t, e, orderID, domain.StatusCancelled)
+	}
+}
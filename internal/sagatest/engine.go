@@ -0,0 +1,271 @@
+// Package sagatest runs the orchestrator in process, on in-memory
+// repositories and a fake queue, so that a workflow can be driven one task at
+// a time and the exact steps and compensations it ran can be checked without
+// Postgres or Redis.
+package sagatest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/executors"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/handlers"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories/memory"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/usecases"
+)
+
+// maxTasks bounds Drain, so that a workflow that keeps failing and retrying
+// stops the test instead of hanging it.
+const maxTasks = 10000
+
+// Execution is one run of a step executor or compensator.
+type Execution struct {
+	OrderID string
+	Kind    string // step or compensation
+	Name    string
+	Err     error
+}
+
+func (x Execution) String() string {
+	if x.Err != nil {
+		return fmt.Sprintf("%s %s failed: %v", x.Kind, x.Name, x.Err)
+	}
+	return fmt.Sprintf("%s %s", x.Kind, x.Name)
+}
+
+// failure makes the next attempts of a step or compensation fail.
+type failure struct {
+	err   error
+	times int // attempts left to fail; negative for all of them
+}
+
+// Engine is an Orchestrator with its handlers, wired to a FakeQueue. Every
+// executable step and compensation of the loaded workflow definitions
+// succeeds with no output unless a test registers its own or injects a
+// failure.
+type Engine struct {
+	Orchestrator *usecases.Orchestrator
+	Definitions  *usecases.Catalog
+	Queue        *FakeQueue
+	Store        *memory.Store
+	registry     *executors.Registry
+	mux          *asynq.ServeMux
+
+	mu         sync.Mutex
+	failures   map[string]*failure // by kind and name
+	executions []Execution
+}
+
+func New() *Engine {
+	store := memory.NewStore()
+	q := NewFakeQueue()
+	return newEngine(store, q, usecases.MemoryDependencies(store, q))
+}
+
+// NewEventSourced returns an Engine that stores workflows as event streams,
+// as the orchestrator does with WORKFLOW_STORE=events.
+func NewEventSourced() *Engine {
+	store := memory.NewStore()
+	q := NewFakeQueue()
+	deps := usecases.MemoryDependencies(store, q)
+	deps.Workflows = memory.NewEventSourcedWorkflowRepo(store)
+	return newEngine(store, q, deps)
+}
+
+func newEngine(store *memory.Store, q *FakeQueue, deps usecases.Dependencies) *Engine {
+	deps.Definitions = usecases.NewCatalog()
+	orchestrator := usecases.NewOrchestrator(deps)
+	e := &Engine{
+		Orchestrator: orchestrator,
+		Definitions:  deps.Definitions,
+		Queue:        q,
+		Store:        store,
+		registry:     executors.NewRegistry(),
+		mux:          asynq.NewServeMux(),
+		failures:     make(map[string]*failure),
+	}
+	h := handlers.New(orchestrator, e.registry, deps.Steps, deps.Compensations)
+	h.SetAttemptFunc(taskAttempt)
+	h.Register(e.mux)
+	for _, def := range e.Definitions.All() {
+		e.registerSteps(def)
+	}
+	return e
+}
+
+// RegisterDefinition adds a version of def to the definitions of the engine,
+// with executors and compensators that succeed with no output for its steps.
+func (e *Engine) RegisterDefinition(def *domain.WorkflowDefinition) error {
+	if err := e.Definitions.Register(def); err != nil {
+		return err
+	}
+	e.registerSteps(def)
+	return nil
+}
+
+// UseDefinitions makes the engine run defs as the orchestrator runs the
+// files of its --workflow flag, the first for new orders, with executors and
+// compensators that succeed with no output for their steps.
+func (e *Engine) UseDefinitions(defs ...*domain.WorkflowDefinition) error {
+	if err := e.Definitions.Use(defs); err != nil {
+		return err
+	}
+	for _, def := range defs {
+		e.registerSteps(def)
+	}
+	return nil
+}
+
+func (e *Engine) registerSteps(def *domain.WorkflowDefinition) {
+	for _, s := range def.ExecutableSteps() {
+		e.RegisterStep(s.Name, nil)
+		if s.Compensation != "" {
+			e.RegisterCompensation(s.Compensation, nil)
+		}
+	}
+}
+
+// RegisterStep runs exec for step, or succeeds with no output for a nil exec.
+func (e *Engine) RegisterStep(step domain.Step, exec domain.StepExecutor) {
+	e.registry.RegisterStep(step, domain.StepExecutorFunc(func(ctx context.Context, orderID string) (map[string]any, error) {
+		var (
+			output map[string]any
+			err    = e.injected("step", string(step))
+		)
+		if err == nil && exec != nil {
+			output, err = exec.Execute(ctx, orderID)
+		}
+		e.record(Execution{OrderID: orderID, Kind: "step", Name: string(step), Err: err})
+		return output, err
+	}))
+}
+
+// RegisterCompensation runs c for comp, or succeeds for a nil c.
+func (e *Engine) RegisterCompensation(comp domain.CompensationStep, c domain.Compensator) {
+	e.registry.RegisterCompensation(comp, domain.CompensatorFunc(func(ctx context.Context, orderID string) error {
+		err := e.injected("compensation", string(comp))
+		if err == nil && c != nil {
+			err = c.Compensate(ctx, orderID)
+		}
+		e.record(Execution{OrderID: orderID, Kind: "compensation", Name: string(comp), Err: err})
+		return err
+	}))
+}
+
+// FailStep makes the next times attempts of step fail with err, for every
+// order, or all of them for a negative times. Wrap err with domain.Transient
+// or domain.Rejected to choose how the engine handles it; a plain error is
+// retried until the task runs out of retries.
+func (e *Engine) FailStep(step domain.Step, times int, err error) {
+	e.fail("step", string(step), times, err)
+}
+
+// FailCompensation makes the next times attempts of comp fail with err, or
+// all of them for a negative times.
+func (e *Engine) FailCompensation(comp domain.CompensationStep, times int, err error) {
+	e.fail("compensation", string(comp), times, err)
+}
+
+func (e *Engine) fail(kind, name string, times int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures[kind+" "+name] = &failure{err: err, times: times}
+}
+
+func (e *Engine) injected(kind, name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	f, ok := e.failures[kind+" "+name]
+	if !ok || f.times == 0 {
+		return nil
+	}
+	if f.times > 0 {
+		f.times--
+	}
+	return f.err
+}
+
+func (e *Engine) record(x Execution) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executions = append(e.executions, x)
+}
+
+// Start starts the default workflow for orderID. Nothing runs until the
+// engine is stepped.
+func (e *Engine) Start(ctx context.Context, orderID string, data map[string]any) error {
+	return e.Orchestrator.StartWorkflow(ctx, orderID, data)
+}
+
+// Step publishes the outbox to the queue and processes the next due task. It
+// returns the task, with the error its handler returned, or nil when no task
+// is due.
+func (e *Engine) Step(ctx context.Context) (*FakeTask, error) {
+	if _, err := e.Orchestrator.RelayOutbox(ctx); err != nil {
+		return nil, fmt.Errorf("failed to relay outbox: %w", err)
+	}
+	return e.Queue.Process(ctx, e.mux), nil
+}
+
+// Drain steps the engine until no task is due and returns the tasks it
+// processed, in order.
+func (e *Engine) Drain(ctx context.Context) ([]*FakeTask, error) {
+	var tasks []*FakeTask
+	for len(tasks) < maxTasks {
+		task, err := e.Step(ctx)
+		if err != nil {
+			return tasks, err
+		}
+		if task == nil {
+			return tasks, nil
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, fmt.Errorf("still busy after %d tasks", maxTasks)
+}
+
+// Advance moves the time of the queue forward by d and drains the engine, so
+// that timers due by then fire.
+func (e *Engine) Advance(ctx context.Context, d time.Duration) ([]*FakeTask, error) {
+	e.Queue.Advance(d)
+	return e.Drain(ctx)
+}
+
+// Executions returns the step and compensation runs for orderID, in order.
+func (e *Engine) Executions(orderID string) []Execution {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var runs []Execution
+	for _, x := range e.executions {
+		if x.OrderID == orderID {
+			runs = append(runs, x)
+		}
+	}
+	return runs
+}
+
+// Executed returns Executions(orderID) as strings, such as "step
+// reserve_pickup_slot" or "compensation release_pickup_slot failed: boom", to
+// compare against the expected sequence.
+func (e *Engine) Executed(orderID string) []string {
+	runs := e.Executions(orderID)
+	lines := make([]string, len(runs))
+	for i, x := range runs {
+		lines[i] = x.String()
+	}
+	return lines
+}
+
+// State returns the workflow of orderID, or nil if it has none.
+func (e *Engine) State(ctx context.Context, orderID string) (*domain.WorkflowState, error) {
+	return memory.NewWorkflowRepo(e.Store).GetStateByOrderID(ctx, orderID)
+}
+
+// Transitions returns the history of the workflow of orderID, oldest first.
+func (e *Engine) Transitions(ctx context.Context, orderID string) ([]*domain.WorkflowTransition, error) {
+	return memory.NewWorkflowTransitionRepo(e.Store).GetTransitionsByOrderID(ctx, orderID)
+}
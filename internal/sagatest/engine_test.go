@@ -0,0 +1,324 @@
+package sagatest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/sagatest"
+)
+
+func TestDefaultWorkflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(e *sagatest.Engine)
+		executed []string
+		status   domain.WorkflowStatus
+	}{
+		{
+			name: "happy path",
+			executed: []string{
+				"step reserve_pickup_slot",
+				"step assign_agent",
+				"step notify_customer",
+			},
+			status: domain.StatusCompleted,
+		},
+		{
+			name: "terminal failure compensates completed steps in reverse",
+			setup: func(e *sagatest.Engine) {
+				e.FailStep(domain.StepNotifyCustomer, 1, domain.Terminal(errors.New("no such customer")))
+			},
+			executed: []string{
+				"step reserve_pickup_slot",
+				"step assign_agent",
+				"step notify_customer failed: no such customer",
+				"compensation unassign_agent",
+				"compensation release_pickup_slot",
+			},
+			status: domain.StatusCompensated,
+		},
+		{
+			name: "transient failure is retried",
+			setup: func(e *sagatest.Engine) {
+				e.FailStep(domain.StepAssignAgent, 1, domain.Transient(errors.New("timeout")))
+			},
+			executed: []string{
+				"step reserve_pickup_slot",
+				"step assign_agent failed: timeout",
+				"step assign_agent",
+				"step notify_customer",
+			},
+			status: domain.StatusCompleted,
+		},
+		{
+			name: "compensation failing for good parks the rollback",
+			setup: func(e *sagatest.Engine) {
+				e.FailStep(domain.StepNotifyCustomer, 1, domain.Terminal(errors.New("no such customer")))
+				e.FailCompensation(domain.CompReleaseSlot, -1, domain.Terminal(errors.New("slot service gone")))
+			},
+			executed: []string{
+				"step reserve_pickup_slot",
+				"step assign_agent",
+				"step notify_customer failed: no such customer",
+				"compensation unassign_agent",
+				"compensation release_pickup_slot failed: slot service gone",
+			},
+			status: domain.StatusCompensationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			e := sagatest.New()
+			if tt.setup != nil {
+				tt.setup(e)
+			}
+			if err := e.Start(ctx, "order-1", nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.Drain(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := e.Executed("order-1"); !slices.Equal(got, tt.executed) {
+				t.Errorf("executed %q, want %q", got, tt.executed)
+			}
+			state, err := e.State(ctx, "order-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.Status != tt.status {
+				t.Errorf("status is %s, want %s", state.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestParkedRollbackRecordsFailure(t *testing.T) {
+	ctx := context.Background()
+	e := sagatest.New()
+	e.FailStep(domain.StepAssignAgent, 1, domain.Terminal(errors.New("no agents")))
+	e.FailCompensation(domain.CompReleaseSlot, -1, domain.Terminal(errors.New("slot service gone")))
+	if err := e.Start(ctx, "order-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := e.State(ctx, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := state.CompensationFailure
+	if f == nil || f.Step != domain.StepReserveSlot || f.Compensation != domain.CompReleaseSlot {
+		t.Fatalf("compensation failure is %+v, want release_pickup_slot of reserve_pickup_slot", f)
+	}
+	if e.Queue.Len() != 0 {
+		t.Errorf("%d tasks still queued after the rollback was parked", e.Queue.Len())
+	}
+}
+
+func TestLiftedPauseOnlyResumesWorkflowsItHeld(t *testing.T) {
+	ctx := context.Background()
+	e := sagatest.New()
+	o := e.Orchestrator
+	if err := o.PauseStep(ctx, domain.StepReserveSlot, "slot service down"); err != nil {
+		t.Fatal(err)
+	}
+	for _, orderID := range []string{"held", "operator"} {
+		if err := e.Start(ctx, orderID, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := o.PauseWorkflow(ctx, "operator", "fraud check"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := o.ResumeStep(ctx, domain.StepReserveSlot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed != 1 {
+		t.Errorf("resumed %d workflows, want 1", resumed)
+	}
+	if _, err := e.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for orderID, want := range map[string]domain.WorkflowStatus{"held": domain.StatusCompleted, "operator": domain.StatusPaused} {
+		state, err := e.State(ctx, orderID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.Status != want {
+			t.Errorf("workflow %s is %s, want %s", orderID, state.Status, want)
+		}
+	}
+}
+
+func TestResumeWorkflow(t *testing.T) {
+	stores := map[string]func() *sagatest.Engine{
+		"table":  sagatest.New,
+		"events": sagatest.NewEventSourced,
+	}
+	for name, newEngine := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Run("a step still queued is left to its task", func(t *testing.T) {
+				ctx := context.Background()
+				e := newEngine()
+				if err := e.Start(ctx, "order-1", nil); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := e.Orchestrator.RelayOutbox(ctx); err != nil {
+					t.Fatal(err)
+				}
+				if err := e.Orchestrator.PauseWorkflow(ctx, "order-1", ""); err != nil {
+					t.Fatal(err)
+				}
+				if err := e.Orchestrator.ResumeWorkflow(ctx, "order-1"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := e.Orchestrator.RelayOutbox(ctx); err != nil {
+					t.Fatal(err)
+				}
+				if n := e.Queue.Len(); n != 1 {
+					t.Errorf("%d tasks queued after resuming, want the one of the first step", n)
+				}
+				drain(t, e)
+				checkExecuted(t, e, "order-1", []string{"step reserve_pickup_slot", "step assign_agent", "step notify_customer"})
+				checkStatus(t, e, "order-1", domain.StatusCompleted)
+			})
+
+			t.Run("a step not run while paused runs once resumed", func(t *testing.T) {
+				ctx := context.Background()
+				e := newEngine()
+				if err := e.Start(ctx, "order-1", nil); err != nil {
+					t.Fatal(err)
+				}
+				if err := e.Orchestrator.PauseWorkflow(ctx, "order-1", ""); err != nil {
+					t.Fatal(err)
+				}
+				drain(t, e)
+				state := checkStatus(t, e, "order-1", domain.StatusPaused)
+				if state.PausedBy != domain.PausedByOperator || !slices.Equal(state.HeldSteps, []domain.Step{domain.StepReserveSlot}) {
+					t.Errorf("workflow is paused by %q holding %v, want %q holding its first step", state.PausedBy, state.HeldSteps, domain.PausedByOperator)
+				}
+				if err := e.Orchestrator.ResumeWorkflow(ctx, "order-1"); err != nil {
+					t.Fatal(err)
+				}
+				drain(t, e)
+				checkExecuted(t, e, "order-1", []string{"step reserve_pickup_slot", "step assign_agent", "step notify_customer"})
+				checkStatus(t, e, "order-1", domain.StatusCompleted)
+			})
+		})
+	}
+}
+
+func TestRetryRolledBackWorkflow(t *testing.T) {
+	stores := map[string]func() *sagatest.Engine{
+		"table":  sagatest.New,
+		"events": sagatest.NewEventSourced,
+	}
+	for name, newEngine := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			e := newEngine()
+			e.FailStep(domain.StepNotifyCustomer, 1, domain.Terminal(errors.New("no such customer")))
+			if err := e.Start(ctx, "order-1", nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.Drain(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := e.Orchestrator.RetryWorkflow(ctx, "order-1", ""); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.Drain(ctx); err != nil {
+				t.Fatal(err)
+			}
+			state, err := e.State(ctx, "order-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.Status != domain.StatusCompleted {
+				t.Errorf("status is %s, want %s", state.Status, domain.StatusCompleted)
+			}
+			want := []domain.Step{domain.StepReserveSlot, domain.StepAssignAgent, domain.StepNotifyCustomer}
+			if !slices.Equal(state.CompletedSteps, want) {
+				t.Errorf("completed steps %v, want %v", state.CompletedSteps, want)
+			}
+		})
+	}
+}
+
+func TestRetryFromStep(t *testing.T) {
+	def := &domain.WorkflowDefinition{
+		Name:    "retry",
+		Version: 1,
+		Steps: []domain.StepDefinition{
+			{Name: "prepare", Compensation: "unprepare"},
+			{Name: "pack", Compensation: "unpack"},
+			{Name: "label"},
+			{Name: "approval", Signal: &domain.SignalDefinition{Name: "approved"}},
+		},
+	}
+	stores := map[string]func() *sagatest.Engine{
+		"table":  sagatest.New,
+		"events": sagatest.NewEventSourced,
+	}
+	for name, newEngine := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			e := newEngine()
+			if err := e.UseDefinitions(def); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Start(ctx, "order-1", nil); err != nil {
+				t.Fatal(err)
+			}
+			drain(t, e)
+			checkStatus(t, e, "order-1", domain.StatusWaiting)
+
+			if err := e.Orchestrator.RetryWorkflow(ctx, "order-1", "pack"); err != nil {
+				t.Fatal(err)
+			}
+			drain(t, e)
+			checkExecuted(t, e, "order-1", []string{
+				"step prepare",
+				"step pack",
+				"step label",
+				"compensation unpack",
+				"step pack",
+				"step label",
+			})
+			state := checkStatus(t, e, "order-1", domain.StatusWaiting)
+			if want := []domain.Step{"prepare", "pack", "label"}; !slices.Equal(state.CompletedSteps, want) || state.RetryFrom != "" {
+				t.Errorf("completed steps %v retrying from %q, want %v and no retry", state.CompletedSteps, state.RetryFrom, want)
+			}
+
+			// the step run again is compensated again
+			if err := e.Orchestrator.CancelWorkflow(ctx, "order-1", ""); err != nil {
+				t.Fatal(err)
+			}
+			drain(t, e)
+			checkExecuted(t, e, "order-1", []string{
+				"step prepare",
+				"step pack",
+				"step label",
+				"compensation unpack",
+				"step pack",
+				"step label",
+				"compensation unpack",
+				"compensation unprepare",
+			})
+			checkStatus(t, e, "order-1", domain.StatusCancelled)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package sagatest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// defaultMaxRetry is the number of retries asynq allows a task enqueued
+// without a retry policy.
+const defaultMaxRetry = 25
+
+type attemptKey struct{}
+
+// FakeTask is a task published to a FakeQueue.
+type FakeTask struct {
+	ID        string
+	Type      string
+	Payload   []byte
+	ProcessAt time.Time // zero to process right away
+	Retried   int
+	MaxRetry  int
+	Err       error // what the handler returned, once the task was processed
+}
+
+// FakeQueue is an in-process stand-in for asynq, for tests. Published tasks
+// wait in it until Process runs them one at a time, in the order they were
+// published, so a test decides when tasks run and sees each of them.
+//
+// Time only passes for the queue with Advance: a task scheduled for later is
+// not due until the queue has been advanced to its time. A failed task is
+// retried right away, as asynq would after its retry delay.
+type FakeQueue struct {
+	mu      sync.Mutex
+	tasks   []*FakeTask
+	skipped time.Duration
+}
+
+func NewFakeQueue() *FakeQueue {
+	return &FakeQueue{}
+}
+
+// Publish adds the task of msg to q. Like queue.Publish, it is a no-op while a task
+// with the same ID is waiting.
+func (q *FakeQueue) Publish(_ context.Context, msg *domain.OutboxMessage) error {
+	var payload queue.StepPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload of outbox message %d: %w", msg.ID, err)
+	}
+	task := &FakeTask{
+		ID:        msg.TaskID,
+		Type:      msg.TaskType,
+		Payload:   msg.Payload,
+		ProcessAt: msg.ProcessAt,
+		MaxRetry:  defaultMaxRetry,
+	}
+	if task.ID == "" {
+		task.ID = fmt.Sprintf("outbox:%d", msg.ID)
+	}
+	if p := payload.Retry; p != nil && p.MaxAttempts > 0 {
+		task.MaxRetry = p.MaxAttempts - 1
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, t := range q.tasks {
+		if t.ID == task.ID {
+			return nil
+		}
+	}
+	q.tasks = append(q.tasks, task)
+	return nil
+}
+
+// taskAttempt returns the attempt of the FakeQueue task being processed with
+// ctx, for the handlers in place of asynq's.
+func taskAttempt(ctx context.Context) queue.Attempt {
+	a, _ := ctx.Value(attemptKey{}).(queue.Attempt)
+	return a
+}
+
+// Now returns the time of q: the current time, moved forward by Advance.
+func (q *FakeQueue) Now() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return time.Now().Add(q.skipped)
+}
+
+// Advance moves the time of q forward by d, so that the tasks scheduled up to
+// then become due.
+func (q *FakeQueue) Advance(d time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.skipped += d
+}
+
+// Len returns the number of tasks waiting in q, due or not.
+func (q *FakeQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tasks)
+}
+
+// Process runs the first due task with handler and returns it with the error
+// the handler returned, or nil if no task is due. A task that fails is put
+// back at the end of the queue, unless the handler returned asynq.SkipRetry
+// or the task has no retries left.
+func (q *FakeQueue) Process(ctx context.Context, handler asynq.Handler) *FakeTask {
+	task := q.next()
+	if task == nil {
+		return nil
+	}
+	ctx = context.WithValue(ctx, attemptKey{}, queue.Attempt{Retried: task.Retried, MaxRetry: task.MaxRetry})
+	err := handler.ProcessTask(ctx, asynq.NewTask(task.Type, task.Payload))
+
+	processed := *task
+	processed.Err = err
+	if err != nil && !errors.Is(err, asynq.SkipRetry) && task.Retried < task.MaxRetry {
+		task.Retried++
+		task.ProcessAt = time.Time{}
+		q.mu.Lock()
+		q.tasks = append(q.tasks, task)
+		q.mu.Unlock()
+	}
+	return &processed
+}
+
+// next removes the first due task from q and returns it.
+func (q *FakeQueue) next() *FakeTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now().Add(q.skipped)
+	for i, t := range q.tasks {
+		if t.ProcessAt.After(now) {
+			continue
+		}
+		q.tasks = append(q.tasks[:i], q.tasks[i+1:]...)
+		return t
+	}
+	return nil
+}
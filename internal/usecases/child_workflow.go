@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+// startChildWorkflow starts the child workflow of step. It is safe to call
+// again for the same step: an existing child is left running, and a finished
+// one reports its outcome to the parent again in case that was lost.
+func (o *Orchestrator) startChildWorkflow(ctx context.Context, parent *domain.WorkflowState, step domain.StepDefinition) error {
+	// in one transaction, so a concurrent start of the same child is retried
+	// and finds the child started
+	return o.transaction(ctx, func(ctx context.Context) error {
+		childID := domain.ChildWorkflowID(parent.OrderID, step.Name)
+		child, err := o.workflows.GetStateByOrderID(ctx, childID)
+		if err != nil {
+			return fmt.Errorf("failed to get child workflow state: %w", err)
+		}
+		if child != nil {
+			switch child.Status {
+			case domain.StatusCompleted:
+				return o.NextStep(ctx, parent.OrderID, step.Name, childOutput(childID))
+			case domain.StatusCompensated, domain.StatusCancelled:
+				return o.Compensate(ctx, parent.OrderID, step.Name, fmt.Errorf("child workflow %s was rolled back", childID))
+			}
+			o.logger.Info("Child workflow already running",
+				zap.String("order_id", parent.OrderID),
+				zap.String("child_workflow_id", childID))
+			return nil
+		}
+
+		childDef, err := o.definitions.latest(step.Workflow)
+		if err != nil {
+			return err
+		}
+		return o.startWorkflow(ctx, childDef, childID, nil, parent, step.Name)
+	})
+}
+
+func childOutput(childID string) map[string]any {
+	return map[string]any{"child_workflow_id": childID}
+}
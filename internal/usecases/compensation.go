@@ -2,78 +2,461 @@ package usecases
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/hibiken/asynq"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
 	"go.uber.org/zap"
 )
 
-func Compensate(ctx context.Context, orderID string, failedStep domain.Step) error {
-	client := queue.NewQueueClient()
-	defer client.Close()
+// Compensate rolls back the workflow of orderID after failedStep failed with
+// stepErr. Completed steps are compensated one at a time, most recent first.
+// Once the rollback of a child workflow that failed on its own is done, the
+// step of its parent that started it fails too.
+func (o *Orchestrator) Compensate(ctx context.Context, orderID string, failedStep domain.Step, stepErr error) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		return o.rollback(ctx, orderID, failedStep, fmt.Sprintf("step %s failed", failedStep), stepErr)
+	})
+}
 
-	cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
+// CompensationSucceeded moves the rollback of orderID past the step that comp
+// undoes and starts the next compensation. A compensation that is not the
+// next one in the chain, such as a duplicate task, is ignored.
+func (o *Orchestrator) CompensationSucceeded(ctx context.Context, orderID string, comp domain.CompensationStep) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		workflow, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if workflow == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		def, err := o.definitions.For(workflow)
+		if err != nil {
+			return err
+		}
+		if len(workflow.PendingCompensations) > 0 {
+			if next, _ := def.Step(workflow.PendingCompensations[0]); next.Compensation == comp {
+				return o.compensationDone(ctx, orderID, next.Name)
+			}
+		}
+		o.logger.Info("Compensation is not next in the rollback, ignoring",
+			zap.String("order_id", orderID),
+			zap.String("compensation", string(comp)))
+		return nil
+	})
+}
 
-	orderRepo := repositories.NewOrderRepo(db)
-	workflowRepo := repositories.NewWorkflowRepo(db)
+// ResumeCompensation restarts the compensation the running rollback of
+// orderID stopped at, for instance after the orchestrator crashed.
+func (o *Orchestrator) ResumeCompensation(ctx context.Context, orderID string) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		workflow, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if workflow == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		if workflow.Status != domain.StatusCompensating {
+			return nil
+		}
+		def, err := o.definitions.For(workflow)
+		if err != nil {
+			return err
+		}
+		return o.compensateNext(ctx, def, workflow)
+	})
+}
+
+// RetryCompensation restarts a rollback that was parked after a compensation
+// failed for good, at the compensation it failed on. The rollbacks of child
+// workflows it waits for are retried as well.
+func (o *Orchestrator) RetryCompensation(ctx context.Context, orderID string) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		workflow, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if workflow == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		if workflow.Status != domain.StatusCompensationFailed {
+			return fmt.Errorf("workflow %s is %s, not %s", orderID, workflow.Status, domain.StatusCompensationFailed)
+		}
+		return o.retryRollback(ctx, workflow)
+	})
+}
+
+// CompensationFailed parks the rollback of orderID after comp failed for good,
+// and the rollbacks of the parent workflows waiting for it, until an operator
+// retries it with RetryCompensation.
+func (o *Orchestrator) CompensationFailed(ctx context.Context, orderID string, comp domain.CompensationStep, cause error) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		workflow, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if workflow == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		if workflow.Status != domain.StatusCompensating || len(workflow.PendingCompensations) == 0 {
+			return nil
+		}
+		def, err := o.definitions.For(workflow)
+		if err != nil {
+			return err
+		}
+		step, _ := def.Step(workflow.PendingCompensations[0])
+		if step.Compensation != comp {
+			return nil
+		}
 
-	order, err := orderRepo.GetOrderByID(ctx, orderID)
+		failure := &domain.CompensationFailure{Step: step.Name, Compensation: comp, Error: cause.Error(), FailedAt: time.Now()}
+		for {
+			from := positionOf(workflow)
+			workflow.Status = domain.StatusCompensationFailed
+			workflow.CompensationFailure = failure
+			workflow.UpdatedAt = time.Now()
+			if err := o.workflows.SaveState(ctx, workflow); err != nil {
+				return fmt.Errorf("failed to update workflow state: %w", err)
+			}
+			reason := fmt.Sprintf("compensation of %s failed", failure.Step)
+			if err := o.recordTransition(ctx, workflow.OrderID, from, positionOf(workflow), reason, errors.New(failure.Error)); err != nil {
+				return err
+			}
+			o.logger.Error("Compensation failed, rollback needs an operator",
+				zap.String("order_id", workflow.OrderID),
+				zap.String("step", string(failure.Step)),
+				zap.String("compensation", string(failure.Compensation)),
+				zap.String("error", failure.Error))
+
+			parent, err := o.waitingParent(ctx, workflow)
+			if err != nil || parent == nil {
+				return err
+			}
+			failure = &domain.CompensationFailure{
+				Step:     workflow.ParentStep,
+				Error:    fmt.Sprintf("child workflow %s: %s", workflow.OrderID, failure.Error),
+				FailedAt: failure.FailedAt,
+			}
+			workflow = parent
+		}
+	})
+}
+
+// rollback marks the workflow compensating and its order failed, unless it
+// was cancelled or the workflow is a child, then starts compensating its
+// completed steps. Child workflows still running are rolled back first, and
+// their own children before them. It does nothing if the workflow has
+// already started rolling back. failedStep is empty when
+// a parent workflow rolls back its child or an operator rolls back the
+// workflow; cause goes to the history.
+func (o *Orchestrator) rollback(ctx context.Context, orderID string, failedStep domain.Step, cause string, stepErr error) error {
+	workflow, err := o.workflows.GetStateByOrderID(ctx, orderID)
 	if err != nil {
-		return fmt.Errorf("failed to get order: %w", err)
+		return fmt.Errorf("failed to get workflow state: %w", err)
 	}
-	if order == nil {
-		return fmt.Errorf("order %s not found", orderID)
+	if workflow == nil {
+		return fmt.Errorf("workflow not found for order %s", orderID)
 	}
-
-	order.Status = "failed"
-	if err := orderRepo.SaveOrder(ctx, order); err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+	cancelled, err := o.orderCancelled(ctx, workflow)
+	if err != nil {
+		return err
 	}
-	workflow, err := workflowRepo.GetStateByOrderID(ctx, orderID)
+	if !cancelled {
+		if err := o.setOrderStatus(ctx, workflow, "failed"); err != nil {
+			return err
+		}
+	}
+	if workflow.Status.IsRollback() {
+		// another parallel branch failed first and already started the rollback
+		o.logger.Info("Workflow already rolled back",
+			zap.String("order_id", orderID),
+			zap.String("failed_step", string(failedStep)))
+		return nil
+	}
+	def, err := o.definitions.For(workflow)
 	if err != nil {
-		return fmt.Errorf("failed to get workflow state: %w", err)
+		return err
 	}
-	if workflow != nil {
-		workflow.Status = domain.StatusCompensated
-		workflow.UpdatedAt = time.Now()
-		if err := workflowRepo.SaveState(ctx, workflow); err != nil {
-			return fmt.Errorf("failed to update workflow state: %w", err)
+
+	from := positionOf(workflow)
+	workflow.Status = domain.StatusCompensating
+	workflow.PausedBy = ""
+	workflow.HeldSteps = nil
+	workflow.ActiveSteps = removeStep(workflow.ActiveSteps, failedStep)
+	workflow.PendingCompensations = nil
+	for _, step := range workflow.ActiveSteps {
+		if stepDef, _ := def.Step(step); !stepDef.IsChild() {
+			continue
 		}
+		child, err := o.workflows.GetStateByOrderID(ctx, domain.ChildWorkflowID(orderID, step))
+		if err != nil {
+			return fmt.Errorf("failed to get child workflow state: %w", err)
+		}
+		if child != nil && (child.Status.IsActive() || child.Status == domain.StatusPaused) {
+			workflow.PendingCompensations = append(workflow.PendingCompensations, step)
+		}
+	}
+	for i := len(workflow.CompletedSteps) - 1; i >= 0; i-- {
+		if step, _ := def.Step(workflow.CompletedSteps[i]); needsCompensation(step) {
+			workflow.PendingCompensations = append(workflow.PendingCompensations, step.Name)
+		}
+	}
+	workflow.UpdatedAt = time.Now()
+	if err := o.workflows.SaveState(ctx, workflow); err != nil {
+		return fmt.Errorf("failed to update workflow state: %w", err)
+	}
+	if err := o.recordTransition(ctx, orderID, from, positionOf(workflow), cause, stepErr); err != nil {
+		return err
 	}
 
-	var compSteps []domain.CompensationStep
-	switch failedStep {
-	case domain.StepAssignAgent:
-		compSteps = []domain.CompensationStep{domain.CompReleaseSlot}
-	case domain.StepNotifyCustomer:
-		compSteps = []domain.CompensationStep{domain.CompUnassignAgent, domain.CompReleaseSlot}
-	case domain.StepReserveSlot:
+	o.logger.Info("Rolling back workflow",
+		zap.String("order_id", orderID),
+		zap.String("failed_step", string(failedStep)),
+		zap.Any("compensations", workflow.PendingCompensations))
+	return o.compensateNext(ctx, def, workflow)
+}
+
+// compensateLateStep undoes a parallel branch that succeeded after the
+// workflow had already been rolled back, after the compensations that are
+// already pending.
+func (o *Orchestrator) compensateLateStep(ctx context.Context, orderID string, def *domain.WorkflowDefinition, step domain.Step) error {
+	if stepDef, _ := def.Step(step); !needsCompensation(stepDef) {
 		return nil
 	}
 
-	for _, comp := range compSteps {
-		payload, err := json.Marshal(queue.StepPayload{OrderID: orderID, Step: domain.Step(comp)})
+	return o.transaction(ctx, func(ctx context.Context) error {
+		workflow, err := o.workflows.AppendCompensation(ctx, orderID, step)
 		if err != nil {
-			return fmt.Errorf("failed to marshal compensation payload: %w", err)
+			return err
+		}
+		cause := fmt.Sprintf("step %s succeeded during rollback", step)
+		if len(workflow.PendingCompensations) > 1 {
+			// picked up once the compensations before it are done
+			return o.recordTransition(ctx, orderID, positionOf(workflow), positionOf(workflow), cause, nil)
 		}
-		task := asynq.NewTask("compensation", payload)
-		if _, err := client.EnqueueContext(ctx, task); err != nil {
-			return fmt.Errorf("failed to enqueue compensation %s: %w", comp, err)
+		// the rollback was already done, reopen it for this step
+		if done := workflow.Status; done == domain.StatusCompensated || done == domain.StatusCancelled {
+			ok, err := o.workflows.UpdateStatus(ctx, orderID, done, domain.StatusCompensating)
+			if err != nil {
+				return err
+			}
+			if ok {
+				from := position{status: done}
+				to := position{step: step, status: domain.StatusCompensating}
+				if err := o.recordTransition(ctx, orderID, from, to, cause, nil); err != nil {
+					return err
+				}
+			}
 		}
-		logger.Info("Enqueued compensation",
+		return o.compensateNext(ctx, def, workflow)
+	})
+}
+
+// compensateNext starts the first pending compensation of workflow, or
+// finishes the rollback when none is left. A child workflow step is undone by
+// rolling back the child, which continues this chain when it is done.
+func (o *Orchestrator) compensateNext(ctx context.Context, def *domain.WorkflowDefinition, workflow *domain.WorkflowState) error {
+	if len(workflow.PendingCompensations) == 0 {
+		return o.finishRollback(ctx, workflow)
+	}
+	step, ok := def.Step(workflow.PendingCompensations[0])
+	if !ok {
+		return fmt.Errorf("unknown step %s in workflow %s", workflow.PendingCompensations[0], def.Name)
+	}
+	if !step.IsChild() {
+		return o.enqueueCompensation(ctx, workflow.OrderID, step.Compensation)
+	}
+
+	childID := domain.ChildWorkflowID(workflow.OrderID, step.Name)
+	o.logger.Info("Compensating child workflow",
+		zap.String("order_id", workflow.OrderID),
+		zap.String("child_workflow_id", childID))
+	child, err := o.workflows.GetStateByOrderID(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child workflow state: %w", err)
+	}
+	if child == nil {
+		return fmt.Errorf("child workflow %s not found", childID)
+	}
+	switch child.Status {
+	case domain.StatusCompensating:
+		// the child is already rolling back, e.g. when resuming after a crash
+	case domain.StatusCompensationFailed:
+		return o.retryRollback(ctx, child)
+	default:
+		return o.rollback(ctx, childID, "", "parent workflow rolled back", nil)
+	}
+	childDef, err := o.definitions.For(child)
+	if err != nil {
+		return err
+	}
+	return o.compensateNext(ctx, childDef, child)
+}
+
+// compensationDone removes step from the pending compensations of orderID and
+// starts the next one.
+func (o *Orchestrator) compensationDone(ctx context.Context, orderID string, step domain.Step) error {
+	workflow, err := o.workflows.PopCompensation(ctx, orderID, step)
+	if err != nil {
+		return err
+	}
+	if workflow == nil {
+		o.logger.Info("Step is not the next to compensate, ignoring",
 			zap.String("order_id", orderID),
-			zap.String("compensation", string(comp)),
-		)
+			zap.String("step", string(step)))
+		return nil
+	}
+	from := position{step: step, status: workflow.Status}
+	if err := o.recordTransition(ctx, orderID, from, positionOf(workflow), fmt.Sprintf("step %s compensated", step), nil); err != nil {
+		return err
+	}
+	def, err := o.definitions.For(workflow)
+	if err != nil {
+		return err
+	}
+	return o.compensateNext(ctx, def, workflow)
+}
+
+// finishRollback marks the workflow compensated, or cancelled if its order
+// was, and reports the completed rollback to the parent workflow, if any: a
+// parent that is still running fails the step that started the child, and a
+// parent that is rolling back moves on to its next compensation. The rollback
+// of the steps a retry runs again continues the retry instead.
+func (o *Orchestrator) finishRollback(ctx context.Context, workflow *domain.WorkflowState) error {
+	if workflow.RetryFrom != "" {
+		return o.finishRetry(ctx, workflow)
+	}
+	cancelled, err := o.orderCancelled(ctx, workflow)
+	if err != nil {
+		return err
+	}
+	final := domain.StatusCompensated
+	if cancelled {
+		final = domain.StatusCancelled
+	}
+	ok, err := o.workflows.UpdateStatus(ctx, workflow.OrderID, domain.StatusCompensating, final)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	from := position{status: domain.StatusCompensating}
+	to := position{status: final}
+	if err := o.recordTransition(ctx, workflow.OrderID, from, to, "rollback completed", nil); err != nil {
+		return err
+	}
+	o.logger.Info("Workflow rolled back",
+		zap.String("order_id", workflow.OrderID),
+		zap.String("status", string(final)))
+	if workflow.ParentOrderID == "" {
+		return nil
+	}
+	parent, err := o.workflows.GetStateByOrderID(ctx, workflow.ParentOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get parent workflow state: %w", err)
 	}
+	if parent == nil {
+		return fmt.Errorf("workflow not found for order %s", workflow.ParentOrderID)
+	}
+	if parent.Status.IsActive() || parent.Status == domain.StatusPaused {
+		return o.rollback(ctx, parent.OrderID, workflow.ParentStep, fmt.Sprintf("step %s failed", workflow.ParentStep), fmt.Errorf("child workflow %s was rolled back", workflow.OrderID))
+	}
+	if parent.Status == domain.StatusCompensationFailed && parent.CompensationFailure != nil &&
+		parent.CompensationFailure.Step == workflow.ParentStep {
+		// an operator retried the rollback of the child directly
+		from := positionOf(parent)
+		parent.Status = domain.StatusCompensating
+		parent.CompensationFailure = nil
+		parent.UpdatedAt = time.Now()
+		if err := o.workflows.SaveState(ctx, parent); err != nil {
+			return fmt.Errorf("failed to update workflow state: %w", err)
+		}
+		cause := fmt.Sprintf("rollback of child workflow %s retried", workflow.OrderID)
+		if err := o.recordTransition(ctx, parent.OrderID, from, positionOf(parent), cause, nil); err != nil {
+			return err
+		}
+	}
+	return o.compensationDone(ctx, parent.OrderID, workflow.ParentStep)
+}
+
+// retryRollback moves a parked rollback back to compensating and restarts it
+// at the compensation it failed on.
+func (o *Orchestrator) retryRollback(ctx context.Context, workflow *domain.WorkflowState) error {
+	def, err := o.definitions.For(workflow)
+	if err != nil {
+		return err
+	}
+	from := positionOf(workflow)
+	workflow.Status = domain.StatusCompensating
+	workflow.CompensationFailure = nil
+	workflow.UpdatedAt = time.Now()
+	if err := o.workflows.SaveState(ctx, workflow); err != nil {
+		return fmt.Errorf("failed to update workflow state: %w", err)
+	}
+	if err := o.recordTransition(ctx, workflow.OrderID, from, positionOf(workflow), "rollback retried", nil); err != nil {
+		return err
+	}
+	o.logger.Info("Retrying rollback",
+		zap.String("order_id", workflow.OrderID),
+		zap.Any("compensations", workflow.PendingCompensations))
+	return o.compensateNext(ctx, def, workflow)
+}
+
+// waitingParent returns the parent of workflow if its rollback is waiting for
+// the rollback of workflow, and nil otherwise.
+func (o *Orchestrator) waitingParent(ctx context.Context, workflow *domain.WorkflowState) (*domain.WorkflowState, error) {
+	if workflow.ParentOrderID == "" {
+		return nil, nil
+	}
+	parent, err := o.workflows.GetStateByOrderID(ctx, workflow.ParentOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent workflow state: %w", err)
+	}
+	if parent == nil || parent.Status != domain.StatusCompensating ||
+		len(parent.PendingCompensations) == 0 || parent.PendingCompensations[0] != workflow.ParentStep {
+		return nil, nil
+	}
+	return parent, nil
+}
+
+// orderCancelled reports whether the order workflow runs for was cancelled.
+func (o *Orchestrator) orderCancelled(ctx context.Context, workflow *domain.WorkflowState) (bool, error) {
+	order, err := o.orders.GetOrderByID(ctx, workflow.RootOrderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get order: %w", err)
+	}
+	return order != nil && order.Status == orderCancelled, nil
+}
 
+// needsCompensation reports whether a completed step has anything to undo.
+func needsCompensation(step domain.StepDefinition) bool {
+	return step.IsChild() || step.Compensation != ""
+}
+
+func (o *Orchestrator) enqueueCompensation(ctx context.Context, orderID string, comp domain.CompensationStep) error {
+	payload := queue.StepPayload{OrderID: orderID, Step: domain.Step(comp)}
+	if err := o.enqueue(ctx, "compensation", payload, "", time.Time{}); err != nil {
+		return err
+	}
+	o.logger.Info("Enqueued compensation",
+		zap.String("order_id", orderID),
+		zap.String("compensation", string(comp)),
+	)
 	return nil
 }
+
+func removeStep(steps []domain.Step, step domain.Step) []domain.Step {
+	out := make([]domain.Step, 0, len(steps))
+	for _, s := range steps {
+		if s != step {
+			out = append(out, s)
+		}
+	}
+	return out
+}
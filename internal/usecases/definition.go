@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// Catalog holds every registered version of every workflow definition. New
+// workflows start on the latest version of a definition; running workflows
+// keep resolving steps against the version they were started with.
+type Catalog struct {
+	mu          sync.RWMutex
+	defs        map[string]map[int]*domain.WorkflowDefinition
+	defaultName string
+}
+
+// NewCatalog returns a catalog holding the built-in order fulfillment flow as
+// the workflow started for new orders.
+func NewCatalog() *Catalog {
+	c := &Catalog{defs: make(map[string]map[int]*domain.WorkflowDefinition)}
+	if err := c.Set(domain.DefaultWorkflowDefinition()); err != nil {
+		panic(fmt.Sprintf("invalid default workflow definition: %v", err))
+	}
+	return c
+}
+
+// Set registers def and makes its name the workflow started for new orders.
+// It is meant to be called once at startup.
+func (c *Catalog) Set(def *domain.WorkflowDefinition) error {
+	if err := c.Register(def); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultName = def.Name
+	return nil
+}
+
+// Register adds a version of a definition without changing the workflow
+// started for new orders. Registering the same version twice is only allowed
+// if both are identical.
+func (c *Catalog) Register(def *domain.WorkflowDefinition) error {
+	if err := def.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versions, ok := c.defs[def.Name]
+	if !ok {
+		versions = make(map[int]*domain.WorkflowDefinition)
+		c.defs[def.Name] = versions
+	}
+	if existing, ok := versions[def.Version]; ok && !reflect.DeepEqual(existing, def) {
+		return fmt.Errorf("workflow %s version %d is already registered with a different definition, bump its version", def.Name, def.Version)
+	}
+	versions[def.Version] = def
+	return nil
+}
+
+// Use registers defs, making the name of the first one the workflow started
+// for new orders, and checks that every child workflow they reference is
+// registered.
+func (c *Catalog) Use(defs []*domain.WorkflowDefinition) error {
+	for i, def := range defs {
+		register := c.Register
+		if i == 0 {
+			register = c.Set
+		}
+		if err := register(def); err != nil {
+			return err
+		}
+	}
+	return c.Check()
+}
+
+// Check reports child workflows that are not registered and workflows that
+// start themselves through their children.
+func (c *Catalog) Check() error {
+	for _, def := range c.All() {
+		if err := c.checkChildren(def, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) checkChildren(def *domain.WorkflowDefinition, path []string) error {
+	for _, p := range path {
+		if p == def.Name {
+			return fmt.Errorf("workflow %s starts itself as a child workflow", def.Name)
+		}
+	}
+	path = append(path, def.Name)
+	for _, s := range def.ChildSteps() {
+		child, err := c.latest(s.Workflow)
+		if err != nil {
+			return fmt.Errorf("workflow %s, step %s: %w", def.Name, s.Name, err)
+		}
+		if err := c.checkChildren(child, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Default returns the latest version of the workflow started for new orders.
+func (c *Catalog) Default() *domain.WorkflowDefinition {
+	c.mu.RLock()
+	name := c.defaultName
+	c.mu.RUnlock()
+	def, _ := c.latest(name)
+	return def
+}
+
+// All returns every registered version of every definition, ordered by name
+// and version.
+func (c *Catalog) All() []*domain.WorkflowDefinition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var defs []*domain.WorkflowDefinition
+	for _, versions := range c.defs {
+		for _, def := range versions {
+			defs = append(defs, def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Name != defs[j].Name {
+			return defs[i].Name < defs[j].Name
+		}
+		return defs[i].Version < defs[j].Version
+	})
+	return defs
+}
+
+func (c *Catalog) latest(name string) (*domain.WorkflowDefinition, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var latest *domain.WorkflowDefinition
+	for _, def := range c.defs[name] {
+		if latest == nil || def.Version > latest.Version {
+			latest = def
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("workflow definition %s is not registered", name)
+	}
+	return latest, nil
+}
+
+// For returns the definition version state is pinned to.
+func (c *Catalog) For(state *domain.WorkflowState) (*domain.WorkflowDefinition, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	def, ok := c.defs[state.Workflow][state.WorkflowVersion]
+	if !ok {
+		return nil, fmt.Errorf("workflow definition %s version %d is not registered, but order %s still runs it",
+			state.Workflow, state.WorkflowVersion, state.OrderID)
+	}
+	return def, nil
+}
@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+// RunEngineStep performs step when it is run by the engine itself rather than
+// by a registered executor, and reports whether it was.
+func (o *Orchestrator) RunEngineStep(ctx context.Context, orderID string, step domain.Step) (bool, error) {
+	spanCtx, span := o.tracer.Start(ctx, "run_engine_step")
+	defer span.End()
+
+	state, err := o.workflows.GetStateByOrderID(spanCtx, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if state == nil {
+		return false, fmt.Errorf("workflow not found for order %s", orderID)
+	}
+	def, err := o.definitions.For(state)
+	if err != nil {
+		return false, err
+	}
+	stepDef, ok := def.Step(step)
+	if !ok || !stepDef.IsEngineStep() {
+		return false, nil
+	}
+	if !state.Status.IsActive() || !slices.Contains(state.ActiveSteps, step) {
+		o.logger.Info("Step is not active, ignoring",
+			zap.String("order_id", orderID),
+			zap.String("step", string(step)))
+		return true, nil
+	}
+
+	switch {
+	case stepDef.IsTimer():
+		return true, o.startTimer(spanCtx, state, stepDef)
+	case stepDef.IsSignal():
+		return true, o.waitForSignal(spanCtx, state, stepDef)
+	default:
+		return true, o.startChildWorkflow(spanCtx, state, stepDef)
+	}
+}
@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// workerID identifies this process in the workflow history.
+var workerID = func() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}()
+
+// position is where a workflow stands: its current step, or the next step to
+// compensate during a rollback, and its status.
+type position struct {
+	step   domain.Step
+	status domain.WorkflowStatus
+}
+
+func positionOf(state *domain.WorkflowState) position {
+	if !state.Status.IsRollback() {
+		return position{step: state.CurrentStep, status: state.Status}
+	}
+	if len(state.PendingCompensations) == 0 {
+		return position{status: state.Status}
+	}
+	return position{step: state.PendingCompensations[0], status: state.Status}
+}
+
+// recordTransition appends the move of the workflow of orderID from one
+// position to another to its history. Called in the transaction of the
+// change, so the history never disagrees with the state.
+func (o *Orchestrator) recordTransition(ctx context.Context, orderID string, from, to position, cause string, stepErr error) error {
+	t := &domain.WorkflowTransition{
+		OrderID:    orderID,
+		FromStep:   from.step,
+		ToStep:     to.step,
+		FromStatus: from.status,
+		ToStatus:   to.status,
+		Cause:      cause,
+		Worker:     workerID,
+	}
+	if stepErr != nil {
+		t.Error = stepErr.Error()
+	}
+	return o.transitions.AddTransition(ctx, t)
+}
@@ -0,0 +1,334 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+// orderCancelled is the status of a cancelled order. The rollback of its
+// workflow ends in StatusCancelled rather than StatusCompensated.
+const orderCancelled = "cancelled"
+
+// RetryWorkflow runs the workflow of orderID again from step from. Without a
+// step, a running workflow retries the steps it is stuck on and a rolled back
+// one starts over. A parked rollback is retried at the compensation it failed
+// on, and cannot be given a step.
+//
+// Steps completed before from stay done. from, and the steps completed after
+// it, run again: their executions, outputs and timers are forgotten. On a
+// running workflow, the steps completed after from are compensated first,
+// most recent first, and the workflow runs again from from once they are; a
+// child workflow step is not, as running it again finds its child done. Since
+// the steps a rollback undid cannot stay done, a rolled back workflow can only
+// be retried from a step before every compensated one.
+func (o *Orchestrator) RetryWorkflow(ctx context.Context, orderID string, from domain.Step) error {
+	spanCtx, span := o.tracer.Start(ctx, "retry_workflow")
+	defer span.End()
+
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		switch state.Status {
+		case domain.StatusCompensationFailed:
+			if from == "" {
+				return o.retryRollback(ctx, state)
+			}
+			return fmt.Errorf("%w: the rollback of workflow %s is parked, retry it without a step", domain.ErrNotAllowed, orderID)
+		case domain.StatusCompensating, domain.StatusCompleted, domain.StatusPaused:
+			return fmt.Errorf("%w: workflow %s is %s", domain.ErrNotAllowed, orderID, state.Status)
+		}
+		def, err := o.definitions.For(state)
+		if err != nil {
+			return err
+		}
+
+		rolledBack := !state.Status.IsActive()
+		if from == "" {
+			from = state.CurrentStep
+			if rolledBack {
+				from = def.FirstStep()
+			}
+		}
+		if !slices.ContainsFunc(def.Steps, func(s domain.StepDefinition) bool { return s.Name == from }) {
+			return fmt.Errorf("%w: %s is not a step of workflow %s", domain.ErrNotAllowed, from, def.Name)
+		}
+		runnable, err := def.Runnable(from)
+		if err != nil {
+			return err
+		}
+		if !rolledBack && from == state.CurrentStep && len(state.ActiveSteps) > 0 {
+			// only the branches still running; the finished ones stay done
+			runnable = state.ActiveSteps
+		}
+
+		kept := state.CompletedSteps
+		if i := slices.IndexFunc(kept, func(s domain.Step) bool { return slices.Contains(runnable, s) }); i >= 0 {
+			kept = kept[:i]
+		}
+		if rolledBack {
+			for _, step := range kept {
+				if stepDef, _ := def.Step(step); needsCompensation(stepDef) {
+					return fmt.Errorf("%w: step %s was compensated, retry workflow %s from it or an earlier step", domain.ErrNotAllowed, step, orderID)
+				}
+			}
+		}
+		for _, step := range def.ChildSteps() {
+			child, err := o.workflows.GetStateByOrderID(ctx, domain.ChildWorkflowID(orderID, step.Name))
+			if err != nil {
+				return fmt.Errorf("failed to get child workflow state: %w", err)
+			}
+			if child != nil && child.Status.IsRollback() {
+				return fmt.Errorf("%w: child workflow %s was rolled back", domain.ErrNotAllowed, child.OrderID)
+			}
+		}
+
+		// every step not kept may run again, the one that failed included
+		var rerun []domain.Step
+		for _, s := range def.Steps {
+			if !slices.Contains(kept, s.Name) {
+				rerun = append(rerun, s.Name)
+			}
+		}
+		for _, step := range rerun {
+			if err := o.steps.DeleteExecution(ctx, domain.ExecutionKey(orderID, string(step))); err != nil {
+				return err
+			}
+			if stepDef, _ := def.Step(step); stepDef.Compensation != "" {
+				if err := o.compensations.DeleteCompensationExecution(ctx, domain.ExecutionKey(orderID, string(stepDef.Compensation))); err != nil {
+					return err
+				}
+			}
+			delete(state.StepOutputs, step)
+			delete(state.Timers, step)
+		}
+
+		if err := o.setOrderStatus(ctx, state, "pending"); err != nil {
+			return err
+		}
+
+		var undo []domain.Step
+		if !rolledBack {
+			for i := len(state.CompletedSteps) - 1; i >= len(kept); i-- {
+				if stepDef, _ := def.Step(state.CompletedSteps[i]); stepDef.Compensation != "" {
+					undo = append(undo, stepDef.Name)
+				}
+			}
+		}
+		if len(undo) > 0 {
+			before := positionOf(state)
+			state.Status = domain.StatusCompensating
+			state.CurrentStep = from
+			state.ActiveSteps = nil
+			state.CompletedSteps = slices.Clone(kept)
+			state.PendingCompensations = undo
+			state.RetryFrom = from
+			state.UpdatedAt = time.Now()
+			if err := o.workflows.SaveState(ctx, state); err != nil {
+				return fmt.Errorf("failed to update workflow state: %w", err)
+			}
+			cause := fmt.Sprintf("retried from step %s, compensating the steps run again", from)
+			if err := o.recordTransition(ctx, orderID, before, positionOf(state), cause, nil); err != nil {
+				return err
+			}
+			o.logger.Info("Compensating steps before retrying workflow",
+				zap.String("order_id", orderID),
+				zap.String("step", string(from)),
+				zap.Any("compensations", undo))
+			return o.compensateNext(ctx, def, state)
+		}
+
+		before := positionOf(state)
+		state.Status = domain.StatusPending
+		state.CurrentStep = from
+		state.ActiveSteps = slices.Clone(runnable)
+		state.CompletedSteps = slices.Clone(kept)
+		state.PendingCompensations = nil
+		state.CompensationFailure = nil
+		state.UpdatedAt = time.Now()
+		if err := o.workflows.SaveState(ctx, state); err != nil {
+			return fmt.Errorf("failed to update workflow state: %w", err)
+		}
+		if err := o.recordTransition(ctx, orderID, before, positionOf(state), fmt.Sprintf("retried from step %s", from), nil); err != nil {
+			return err
+		}
+		if err := o.enqueueSteps(ctx, def, orderID, runnable); err != nil {
+			return err
+		}
+
+		o.logger.Info("Retrying workflow",
+			zap.String("order_id", orderID),
+			zap.String("step", string(from)),
+			zap.Any("steps", runnable))
+		return nil
+	})
+}
+
+// finishRetry runs the workflow of state again from the step it is retried
+// from, once the steps the retry runs again are compensated. Their
+// compensations are forgotten, so a later rollback runs them again.
+func (o *Orchestrator) finishRetry(ctx context.Context, state *domain.WorkflowState) error {
+	def, err := o.definitions.For(state)
+	if err != nil {
+		return err
+	}
+	runnable, err := def.Runnable(state.RetryFrom)
+	if err != nil {
+		return err
+	}
+	for _, step := range def.Steps {
+		if step.Compensation != "" && !slices.Contains(state.CompletedSteps, step.Name) {
+			if err := o.compensations.DeleteCompensationExecution(ctx, domain.ExecutionKey(state.OrderID, string(step.Compensation))); err != nil {
+				return err
+			}
+		}
+	}
+
+	before := positionOf(state)
+	state.Status = domain.StatusPending
+	state.ActiveSteps = slices.Clone(runnable)
+	state.RetryFrom = ""
+	state.UpdatedAt = time.Now()
+	if err := o.workflows.SaveState(ctx, state); err != nil {
+		return fmt.Errorf("failed to update workflow state: %w", err)
+	}
+	cause := fmt.Sprintf("retried from step %s", state.CurrentStep)
+	if err := o.recordTransition(ctx, state.OrderID, before, positionOf(state), cause, nil); err != nil {
+		return err
+	}
+	if err := o.enqueueSteps(ctx, def, state.OrderID, runnable); err != nil {
+		return err
+	}
+	o.logger.Info("Retrying workflow",
+		zap.String("order_id", state.OrderID),
+		zap.String("step", string(state.CurrentStep)),
+		zap.Any("steps", runnable))
+	return nil
+}
+
+// CancelWorkflow stops the running or paused workflow of orderID, compensates
+// the steps it completed and marks it cancelled once they are. Steps already
+// enqueued are not run, and steps still running are compensated once they
+// finish.
+func (o *Orchestrator) CancelWorkflow(ctx context.Context, orderID, reason string) error {
+	spanCtx, span := o.tracer.Start(ctx, "cancel_workflow")
+	defer span.End()
+
+	return o.operatorRollback(spanCtx, orderID, "cancelled", reason, orderCancelled, func(status domain.WorkflowStatus) bool {
+		return status.IsActive() || status == domain.StatusPaused
+	})
+}
+
+// CompensateWorkflow rolls back the workflow of orderID, running, paused or
+// completed, compensating the steps it completed.
+func (o *Orchestrator) CompensateWorkflow(ctx context.Context, orderID, reason string) error {
+	spanCtx, span := o.tracer.Start(ctx, "compensate_workflow")
+	defer span.End()
+
+	return o.operatorRollback(spanCtx, orderID, "compensated by operator", reason, "", func(status domain.WorkflowStatus) bool {
+		return status.IsActive() || status == domain.StatusPaused || status == domain.StatusCompleted
+	})
+}
+
+// operatorRollback rolls back the workflow of orderID if allowed accepts its
+// status, first setting the status of its order to orderStatus if not empty
+// and the workflow is not a child.
+func (o *Orchestrator) operatorRollback(ctx context.Context, orderID, cause, reason, orderStatus string, allowed func(domain.WorkflowStatus) bool) error {
+	if reason != "" {
+		cause += ": " + reason
+	}
+	return o.transaction(ctx, func(ctx context.Context) error {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		if !allowed(state.Status) {
+			return fmt.Errorf("%w: workflow %s is %s", domain.ErrNotAllowed, orderID, state.Status)
+		}
+		if orderStatus != "" {
+			if err := o.setOrderStatus(ctx, state, orderStatus); err != nil {
+				return err
+			}
+		}
+		return o.rollback(ctx, orderID, "", cause, nil)
+	})
+}
+
+// SkipStep marks the active step of the workflow of orderID as succeeded with
+// output without running it, and moves the workflow on. A task of the step
+// that is still queued finds the step done and does nothing.
+func (o *Orchestrator) SkipStep(ctx context.Context, orderID string, step domain.Step, output map[string]any) error {
+	spanCtx, span := o.tracer.Start(ctx, "skip_step")
+	defer span.End()
+
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		if !state.Status.IsActive() || !slices.Contains(state.ActiveSteps, step) {
+			return fmt.Errorf("%w: step %s of workflow %s is not active", domain.ErrNotAllowed, step, orderID)
+		}
+		def, err := o.definitions.For(state)
+		if err != nil {
+			return err
+		}
+		if stepDef, _ := def.Step(step); stepDef.IsChild() {
+			return fmt.Errorf("%w: step %s runs a child workflow", domain.ErrNotAllowed, step)
+		}
+
+		key := domain.ExecutionKey(orderID, string(step))
+		exec, err := o.steps.GetExecution(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check step execution: %w", err)
+		}
+		if exec != nil {
+			return fmt.Errorf("%w: step %s of workflow %s already %s", domain.ErrNotAllowed, step, orderID, exec.Result)
+		}
+		if err := o.steps.SaveExecution(ctx, &domain.StepExecution{DedupeKey: key, Result: "skipped", Output: output}); err != nil {
+			return fmt.Errorf("failed to save step execution: %w", err)
+		}
+
+		from := positionOf(state)
+		to := from
+		if state.Status == domain.StatusWaiting && !waitsForSignal(def, removeStep(state.ActiveSteps, step)) {
+			if _, err := o.workflows.UpdateStatus(ctx, orderID, domain.StatusWaiting, domain.StatusPending); err != nil {
+				return err
+			}
+			to.status = domain.StatusPending
+		}
+		if err := o.recordTransition(ctx, orderID, from, to, fmt.Sprintf("step %s skipped", step), nil); err != nil {
+			return err
+		}
+
+		o.logger.Info("Skipped step",
+			zap.String("order_id", orderID),
+			zap.String("step", string(step)))
+		return o.NextStep(ctx, orderID, step, output)
+	})
+}
+
+// waitsForSignal reports whether any of steps is a signal step.
+func waitsForSignal(def *domain.WorkflowDefinition, steps []domain.Step) bool {
+	for _, step := range steps {
+		if s, _ := def.Step(step); s.IsSignal() {
+			return true
+		}
+	}
+	return false
+}
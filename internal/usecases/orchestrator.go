@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"database/sql"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/tracing"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories/memory"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Orchestrator runs workflows. It is built once at startup and every workflow
+// it runs shares its repositories, and so their connection pool, and its
+// queue publisher.
+type Orchestrator struct {
+	orders        domain.OrderRepo
+	workflows     domain.WorkflowRepo
+	transitions   domain.WorkflowTransitionRepo
+	agents        domain.AgentRepo
+	steps         domain.StepExecutionRepo
+	compensations domain.CompensationExecutionRepo
+	pauses        domain.PauseRepo
+	outbox        domain.OutboxRepo
+	transactor    domain.Transactor
+	publisher     domain.TaskPublisher
+	definitions   *Catalog
+	logger        *zap.Logger
+	tracer        trace.Tracer
+}
+
+// Dependencies are what an Orchestrator runs on. Definitions defaults to a
+// catalog of the built-in flow alone, Logger and Tracer to a no-op logger and
+// the global tracer. Agents is only read to report the
+// agents of a workflow, and can be left nil. Steps and Compensations are the
+// execution records the handlers dedupe on, which operators clear to run
+// steps again or mark them skipped. Pauses are the steps operators hold.
+type Dependencies struct {
+	Orders        domain.OrderRepo
+	Workflows     domain.WorkflowRepo
+	Transitions   domain.WorkflowTransitionRepo
+	Agents        domain.AgentRepo
+	Steps         domain.StepExecutionRepo
+	Compensations domain.CompensationExecutionRepo
+	Pauses        domain.PauseRepo
+	Outbox        domain.OutboxRepo
+	Transactor    domain.Transactor
+	Publisher     domain.TaskPublisher
+	Definitions   *Catalog
+	Logger        *zap.Logger
+	Tracer        trace.Tracer
+}
+
+// PostgresDependencies returns the repositories of db, with workflows in the
+// store cfg selects, and publisher.
+func PostgresDependencies(cfg *config.Config, db *sql.DB, publisher domain.TaskPublisher) Dependencies {
+	deps := Dependencies{
+		Orders:        repositories.NewOrderRepo(db),
+		Workflows:     repositories.NewWorkflowRepo(db),
+		Transitions:   repositories.NewWorkflowTransitionRepo(db),
+		Agents:        repositories.NewAgentRepo(db),
+		Steps:         repositories.NewStepExecutionRepo(db),
+		Compensations: repositories.NewCompensationExecutionRepo(db),
+		Pauses:        repositories.NewPauseRepo(db),
+		Outbox:        repositories.NewOutboxRepo(db),
+		Transactor:    repositories.NewTransactor(db),
+		Publisher:     publisher,
+	}
+	if cfg.WorkflowStore == config.WorkflowStoreEvents {
+		deps.Workflows = repositories.NewEventSourcedWorkflowRepo(db)
+	}
+	return deps
+}
+
+// MemoryDependencies returns repositories that keep their data in store, and
+// publisher. Nothing survives a restart, so they suit tests and local runs.
+func MemoryDependencies(store *memory.Store, publisher domain.TaskPublisher) Dependencies {
+	return Dependencies{
+		Orders:        memory.NewOrderRepo(store),
+		Workflows:     memory.NewWorkflowRepo(store),
+		Transitions:   memory.NewWorkflowTransitionRepo(store),
+		Agents:        memory.NewAgentRepo(store),
+		Steps:         memory.NewStepExecutionRepo(store),
+		Compensations: memory.NewCompensationExecutionRepo(store),
+		Pauses:        memory.NewPauseRepo(store),
+		Outbox:        memory.NewOutboxRepo(store),
+		Transactor:    memory.NewTransactor(store),
+		Publisher:     publisher,
+	}
+}
+
+func NewOrchestrator(deps Dependencies) *Orchestrator {
+	o := &Orchestrator{
+		orders:        deps.Orders,
+		workflows:     deps.Workflows,
+		transitions:   deps.Transitions,
+		agents:        deps.Agents,
+		steps:         deps.Steps,
+		compensations: deps.Compensations,
+		pauses:        deps.Pauses,
+		outbox:        deps.Outbox,
+		transactor:    deps.Transactor,
+		publisher:     deps.Publisher,
+		definitions:   deps.Definitions,
+		logger:        deps.Logger,
+		tracer:        deps.Tracer,
+	}
+	if o.definitions == nil {
+		o.definitions = NewCatalog()
+	}
+	if o.logger == nil {
+		o.logger = zap.NewNop()
+	}
+	if o.tracer == nil {
+		o.tracer = tracing.Tracer
+	}
+	return o
+}
+
+// Logger returns the logger of o, for the adapters that run its usecases.
+func (o *Orchestrator) Logger() *zap.Logger {
+	return o.logger
+}
+
+// Tracer returns the tracer of o, for the adapters that run its usecases.
+func (o *Orchestrator) Tracer() trace.Tracer {
+	return o.tracer
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+const outboxBatchSize = 100
+
+// enqueue records a task in the outbox. Called in a transaction, the task is
+// only published if the transaction commits.
+func (o *Orchestrator) enqueue(ctx context.Context, taskType string, payload queue.StepPayload, taskID string, processAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", taskType, err)
+	}
+	msg := &domain.OutboxMessage{TaskType: taskType, Payload: data, TaskID: taskID, ProcessAt: processAt}
+	if err := o.outbox.AddMessage(ctx, msg); err != nil {
+		return fmt.Errorf("failed to enqueue %s %s: %w", taskType, payload.Step, err)
+	}
+	return nil
+}
+
+// RunOutboxRelay publishes outbox messages to the queue, oldest first, until
+// ctx is done. It waits interval whenever the outbox is empty.
+func (o *Orchestrator) RunOutboxRelay(ctx context.Context, interval time.Duration) {
+	for {
+		sent, err := o.RelayOutbox(ctx)
+		if err != nil {
+			o.logger.Error("Failed to relay outbox", zap.Error(err))
+		}
+		if err == nil && sent == outboxBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// RelayOutbox publishes up to one batch of outbox messages and returns how
+// many were sent. Each message is published and marked sent in a transaction
+// of its own, which keeps it locked from other relays only while it is
+// published, and which a later failure cannot roll back.
+func (o *Orchestrator) RelayOutbox(ctx context.Context) (int, error) {
+	sent := 0
+	for sent < outboxBatchSize {
+		relayed, err := o.relayMessage(ctx)
+		if err != nil {
+			return sent, err
+		}
+		if !relayed {
+			break
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// relayMessage publishes the oldest unsent message that no other relay holds,
+// and reports whether there was one.
+func (o *Orchestrator) relayMessage(ctx context.Context) (bool, error) {
+	relayed := false
+	err := o.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		msgs, err := o.outbox.GetUnsentMessages(ctx, 1)
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+		if err := o.publisher.Publish(ctx, msgs[0]); err != nil {
+			return err
+		}
+		if err := o.outbox.MarkSent(ctx, []int64{msgs[0].ID}); err != nil {
+			return err
+		}
+		relayed = true
+		return nil
+	})
+	return relayed && err == nil, err
+}
@@ -0,0 +1,287 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+// resumeBatchSize is how many paused workflows a pause lifted at once reads
+// per query.
+const resumeBatchSize = 100
+
+// PauseWorkflow pauses the running workflow of orderID. Its steps still
+// queued are not run and steps still running finish, but the workflow does
+// not move past them until it is resumed. Signals are refused meanwhile.
+func (o *Orchestrator) PauseWorkflow(ctx context.Context, orderID, reason string) error {
+	spanCtx, span := o.tracer.Start(ctx, "pause_workflow")
+	defer span.End()
+
+	cause := "paused by operator"
+	if reason != "" {
+		cause += ": " + reason
+	}
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		if !state.Status.IsActive() {
+			return fmt.Errorf("%w: workflow %s is %s", domain.ErrNotAllowed, orderID, state.Status)
+		}
+		return o.pause(ctx, state, domain.PausedByOperator, nil, cause)
+	})
+}
+
+// ResumeWorkflow resumes the paused workflow of orderID, enqueueing the steps
+// it held. It is refused while a pause holds one of its active steps.
+func (o *Orchestrator) ResumeWorkflow(ctx context.Context, orderID string) error {
+	spanCtx, span := o.tracer.Start(ctx, "resume_workflow")
+	defer span.End()
+
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		if state.Status != domain.StatusPaused {
+			return fmt.Errorf("%w: workflow %s is %s", domain.ErrNotAllowed, orderID, state.Status)
+		}
+		pause, err := o.pauseHolding(ctx, state.ActiveSteps)
+		if err != nil {
+			return err
+		}
+		if pause != nil {
+			return fmt.Errorf("%w: workflow %s would pause again, %s", domain.ErrNotAllowed, orderID, describePause(pause))
+		}
+		return o.resume(ctx, state, "resumed by operator")
+	})
+}
+
+// PauseStep holds step in every workflow: a workflow about to run it pauses
+// instead, until the pause is lifted. Runs of the step already started
+// finish. Adding the pause again only updates its reason.
+func (o *Orchestrator) PauseStep(ctx context.Context, step domain.Step, reason string) error {
+	spanCtx, span := o.tracer.Start(ctx, "pause_step")
+	defer span.End()
+
+	if step == "" {
+		return fmt.Errorf("%w: no step to pause", domain.ErrNotAllowed)
+	}
+	return o.addPause(spanCtx, step, reason)
+}
+
+// PauseAll holds every step run by an executor, in every workflow, like
+// PauseStep does for one step.
+func (o *Orchestrator) PauseAll(ctx context.Context, reason string) error {
+	spanCtx, span := o.tracer.Start(ctx, "pause_all")
+	defer span.End()
+
+	return o.addPause(spanCtx, "", reason)
+}
+
+// ResumeStep lifts the pause of step and resumes the workflows it paused,
+// unless another pause still holds them. Workflows paused with PauseWorkflow
+// stay paused. It returns how many workflows it resumed.
+func (o *Orchestrator) ResumeStep(ctx context.Context, step domain.Step) (int, error) {
+	spanCtx, span := o.tracer.Start(ctx, "resume_step")
+	defer span.End()
+
+	if step == "" {
+		return 0, fmt.Errorf("%w: no step to resume", domain.ErrNotAllowed)
+	}
+	return o.liftPause(spanCtx, step)
+}
+
+// ResumeAll lifts the pause of every step and resumes the workflows they
+// paused, unless a pause of one step still holds them. Workflows paused with
+// PauseWorkflow stay paused. It returns how many workflows it resumed.
+func (o *Orchestrator) ResumeAll(ctx context.Context) (int, error) {
+	spanCtx, span := o.tracer.Start(ctx, "resume_all")
+	defer span.End()
+
+	return o.liftPause(spanCtx, "")
+}
+
+// Pauses returns the pauses in force, oldest first.
+func (o *Orchestrator) Pauses(ctx context.Context) ([]*domain.WorkflowPause, error) {
+	pauses, err := o.pauses.GetPauses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pauses: %w", err)
+	}
+	return pauses, nil
+}
+
+func (o *Orchestrator) addPause(ctx context.Context, step domain.Step, reason string) error {
+	if err := o.pauses.AddPause(ctx, &domain.WorkflowPause{Step: step, Reason: reason}); err != nil {
+		return err
+	}
+	o.logger.Info("Paused steps",
+		zap.String("step", string(step)),
+		zap.String("reason", reason))
+	return nil
+}
+
+// liftPause removes the pause of step, of every step if empty, and resumes
+// the paused workflows it held that no other pause holds. A workflow was held
+// by the pause if a pause of steps paused it, holding a step the pause holds.
+func (o *Orchestrator) liftPause(ctx context.Context, step domain.Step) (int, error) {
+	lifted := &domain.WorkflowPause{Step: step}
+	var resumed int
+	// in one transaction, so the pause is not gone while the workflows it
+	// held stay paused
+	err := o.transaction(ctx, func(ctx context.Context) error {
+		resumed = 0
+		if _, err := o.pauses.RemovePause(ctx, step); err != nil {
+			return err
+		}
+		filter := domain.WorkflowFilter{Statuses: []domain.WorkflowStatus{domain.StatusPaused}, Limit: resumeBatchSize}
+		for {
+			states, next, err := o.ListWorkflows(ctx, filter)
+			if err != nil {
+				return err
+			}
+			for _, state := range states {
+				if !heldBy(state, lifted) {
+					continue
+				}
+				pause, err := o.pauseHolding(ctx, state.ActiveSteps)
+				if err != nil {
+					return err
+				}
+				if pause != nil {
+					continue
+				}
+				if err := o.resume(ctx, state, "resumed as its pause was lifted"); err != nil {
+					return err
+				}
+				resumed++
+			}
+			if next == nil {
+				return nil
+			}
+			filter.After = next
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	o.logger.Info("Lifted pause",
+		zap.String("step", string(step)),
+		zap.Int("resumed", resumed))
+	return resumed, nil
+}
+
+// pauseHolding returns the oldest pause holding one of steps, if any.
+func (o *Orchestrator) pauseHolding(ctx context.Context, steps []domain.Step) (*domain.WorkflowPause, error) {
+	pauses, err := o.Pauses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pause := range pauses {
+		if slices.ContainsFunc(steps, pause.Holds) {
+			return pause, nil
+		}
+	}
+	return nil, nil
+}
+
+// heldBy reports whether pause paused the paused workflow of state, which an
+// operator can also have paused itself.
+func heldBy(state *domain.WorkflowState, pause *domain.WorkflowPause) bool {
+	if state.PausedBy != domain.PausedByStep && state.PausedBy != domain.PausedByAll {
+		return false
+	}
+	return slices.ContainsFunc(state.HeldSteps, pause.Holds)
+}
+
+// pause moves the running workflow of state to StatusPaused, recording origin
+// as what paused it and held as the steps it did not run. It fails with a
+// version conflict if the workflow changed since state was read, so the
+// transaction runs again on the current one.
+func (o *Orchestrator) pause(ctx context.Context, state *domain.WorkflowState, origin domain.PauseOrigin, held []domain.Step, cause string) error {
+	from := positionOf(state)
+	state.Status = domain.StatusPaused
+	state.PausedBy = origin
+	state.HeldSteps = held
+	state.UpdatedAt = time.Now()
+	if err := o.workflows.SaveState(ctx, state); err != nil {
+		return fmt.Errorf("failed to update workflow state: %w", err)
+	}
+	if err := o.recordTransition(ctx, state.OrderID, from, positionOf(state), cause, nil); err != nil {
+		return err
+	}
+	o.logger.Info("Paused workflow",
+		zap.String("order_id", state.OrderID),
+		zap.String("cause", cause))
+	return nil
+}
+
+// resume moves the paused workflow of state back to pending and enqueues the
+// steps it held, and its engine steps, which run again idempotently. A step
+// whose task is still queued or retrying is left to that task. Only the
+// transaction that moves the workflow out of StatusPaused enqueues them, so
+// a workflow paused once is resumed once. Signal steps wait for their signal
+// again when they run.
+func (o *Orchestrator) resume(ctx context.Context, state *domain.WorkflowState, cause string) error {
+	def, err := o.definitions.For(state)
+	if err != nil {
+		return err
+	}
+	var steps []domain.Step
+	for _, step := range state.ActiveSteps {
+		if stepDef, _ := def.Step(step); stepDef.IsEngineStep() || slices.Contains(state.HeldSteps, step) {
+			steps = append(steps, step)
+		}
+	}
+
+	from := positionOf(state)
+	state.Status = domain.StatusPending
+	state.PausedBy = ""
+	state.HeldSteps = nil
+	state.UpdatedAt = time.Now()
+	if err := o.workflows.SaveState(ctx, state); err != nil {
+		return fmt.Errorf("failed to update workflow state: %w", err)
+	}
+	if err := o.recordTransition(ctx, state.OrderID, from, positionOf(state), cause, nil); err != nil {
+		return err
+	}
+	if err := o.enqueueSteps(ctx, def, state.OrderID, steps); err != nil {
+		return err
+	}
+	o.logger.Info("Resumed workflow",
+		zap.String("order_id", state.OrderID),
+		zap.Any("steps", steps))
+	return nil
+}
+
+// hold records that the task of step did not run because the workflow of
+// state is paused, so that resuming the workflow enqueues the step again.
+func (o *Orchestrator) hold(ctx context.Context, state *domain.WorkflowState, step domain.Step) error {
+	if slices.Contains(state.HeldSteps, step) {
+		return nil
+	}
+	state.HeldSteps = append(state.HeldSteps, step)
+	state.UpdatedAt = time.Now()
+	if err := o.workflows.SaveState(ctx, state); err != nil {
+		return fmt.Errorf("failed to update workflow state: %w", err)
+	}
+	return nil
+}
+
+func describePause(p *domain.WorkflowPause) string {
+	if p.Step == "" {
+		return "every step is paused"
+	}
+	return fmt.Sprintf("step %s is paused", p.Step)
+}
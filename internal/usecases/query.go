@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// WorkflowDetails is a workflow with its order and the agents assigned to it.
+type WorkflowDetails struct {
+	State  *domain.WorkflowState
+	Order  *domain.Order
+	Agents []string
+}
+
+// GetWorkflow returns the workflow of orderID with its order and agents. It
+// fails with ErrWorkflowNotFound if the order has no workflow.
+func (o *Orchestrator) GetWorkflow(ctx context.Context, orderID string) (*WorkflowDetails, error) {
+	spanCtx, span := o.tracer.Start(ctx, "get_workflow")
+	defer span.End()
+
+	state, err := o.workflows.GetStateByOrderID(spanCtx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if state == nil {
+		return nil, fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+	}
+	order, err := o.orders.GetOrderByID(spanCtx, state.RootOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	details := &WorkflowDetails{State: state, Order: order}
+	if o.agents != nil {
+		if details.Agents, err = o.agents.GetAgentsByOrderID(spanCtx, state.RootOrderID); err != nil {
+			return nil, fmt.Errorf("failed to get agents: %w", err)
+		}
+	}
+	return details, nil
+}
+
+// ListWorkflows returns a page of the workflows filter selects, newest first,
+// and the cursor of the next page, or nil on the last one.
+func (o *Orchestrator) ListWorkflows(ctx context.Context, filter domain.WorkflowFilter) ([]*domain.WorkflowState, *domain.WorkflowCursor, error) {
+	spanCtx, span := o.tracer.Start(ctx, "list_workflows")
+	defer span.End()
+
+	limit := filter.Limit
+	if limit > 0 {
+		filter.Limit++ // one more, to know whether there is a next page
+	}
+	states, err := o.workflows.ListWorkflows(spanCtx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	if limit == 0 || len(states) <= limit {
+		return states, nil, nil
+	}
+	states = states[:limit]
+	last := states[limit-1]
+	return states, &domain.WorkflowCursor{CreatedAt: last.CreatedAt, OrderID: last.OrderID}, nil
+}
+
+// RootOrderID returns the order the workflow of orderID runs for, which is
+// what its executors and compensators work on: orderID itself, or the order
+// of the top-level workflow for a child workflow.
+func (o *Orchestrator) RootOrderID(ctx context.Context, orderID string) (string, error) {
+	state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if state == nil {
+		return "", fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+	}
+	return state.RootOrderID, nil
+}
+
+// GetHistory returns the transitions of the workflow of orderID, oldest
+// first. It fails with ErrWorkflowNotFound if the order has no workflow.
+func (o *Orchestrator) GetHistory(ctx context.Context, orderID string) ([]*domain.WorkflowTransition, error) {
+	spanCtx, span := o.tracer.Start(ctx, "get_history")
+	defer span.End()
+
+	state, err := o.workflows.GetStateByOrderID(spanCtx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if state == nil {
+		return nil, fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+	}
+	transitions, err := o.transitions.GetTransitionsByOrderID(spanCtx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transitions: %w", err)
+	}
+	return transitions, nil
+}
+
+// WatchWorkflow calls fn with every transition of the workflow of orderID and
+// the latest state, polling for new transitions every interval, until the
+// workflow is final, ctx is done or fn fails. Transitions made before the
+// call are replayed first. It fails with ErrWorkflowNotFound if the order has
+// no workflow.
+func (o *Orchestrator) WatchWorkflow(ctx context.Context, orderID string, interval time.Duration, fn func(*domain.WorkflowTransition, *domain.WorkflowState) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last int64
+	for {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+		}
+		transitions, err := o.transitions.GetTransitionsAfter(ctx, orderID, last)
+		if err != nil {
+			return fmt.Errorf("failed to get transitions: %w", err)
+		}
+		for _, t := range transitions {
+			if err := fn(t, state); err != nil {
+				return err
+			}
+			last = t.ID
+		}
+		if state.Status.IsFinal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+// waitForSignal parks the workflow on a signal step and, if the step has a
+// timeout, schedules the timer that fails it.
+func (o *Orchestrator) waitForSignal(ctx context.Context, state *domain.WorkflowState, step domain.StepDefinition) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		ok, err := o.workflows.UpdateStatus(ctx, state.OrderID, domain.StatusPending, domain.StatusWaiting)
+		if err != nil {
+			return err
+		}
+		if ok {
+			from := position{step: state.CurrentStep, status: domain.StatusPending}
+			to := position{step: state.CurrentStep, status: domain.StatusWaiting}
+			if err := o.recordTransition(ctx, state.OrderID, from, to, fmt.Sprintf("waiting for signal %s", step.Signal.Name), nil); err != nil {
+				return err
+			}
+		}
+		o.logger.Info("Waiting for signal",
+			zap.String("order_id", state.OrderID),
+			zap.String("step", string(step.Name)),
+			zap.String("signal", step.Signal.Name))
+
+		if step.Signal.Timeout == 0 {
+			return nil
+		}
+		deadline, ok := state.Timers[step.Name]
+		if !ok {
+			deadline = time.Now().Add(time.Duration(step.Signal.Timeout))
+		}
+		return o.scheduleTimer(ctx, state.OrderID, step.Name, deadline)
+	})
+}
+
+// SignalWorkflow delivers the named signal to the workflow of orderID and
+// resumes it. The payload becomes the output of the signal step. It returns
+// domain.ErrSignalNotExpected if no active step waits for the signal.
+func (o *Orchestrator) SignalWorkflow(ctx context.Context, orderID, name string, payload map[string]any) error {
+	spanCtx, span := o.tracer.Start(ctx, "signal_workflow")
+	defer span.End()
+
+	state, err := o.workflows.GetStateByOrderID(spanCtx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if state == nil {
+		return fmt.Errorf("%w for order %s", domain.ErrWorkflowNotFound, orderID)
+	}
+	def, err := o.definitions.For(state)
+	if err != nil {
+		return err
+	}
+
+	var (
+		step        domain.Step
+		stillParked bool
+	)
+	for _, active := range state.ActiveSteps {
+		s, _ := def.Step(active)
+		switch {
+		case !s.IsSignal():
+		case s.Signal.Name == name && step == "":
+			step = active
+		default:
+			stillParked = true
+		}
+	}
+	if !state.Status.IsActive() || step == "" {
+		return fmt.Errorf("%w: order %s, signal %s", domain.ErrSignalNotExpected, orderID, name)
+	}
+
+	o.logger.Info("Signal received",
+		zap.String("order_id", orderID),
+		zap.String("step", string(step)),
+		zap.String("signal", name))
+
+	output := map[string]any{
+		"signal":      name,
+		"payload":     payload,
+		"received_at": time.Now().UTC().Format(time.RFC3339),
+	}
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		if !stillParked {
+			ok, err := o.workflows.UpdateStatus(ctx, orderID, domain.StatusWaiting, domain.StatusPending)
+			if err != nil {
+				return err
+			}
+			if ok {
+				from := position{step: state.CurrentStep, status: domain.StatusWaiting}
+				to := position{step: state.CurrentStep, status: domain.StatusPending}
+				if err := o.recordTransition(ctx, orderID, from, to, fmt.Sprintf("signal %s received", name), nil); err != nil {
+					return err
+				}
+			}
+		}
+		return o.NextStep(ctx, orderID, step, output)
+	})
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+// startTimer schedules the wake-up of a timer step. Starting the same timer
+// again keeps its first wake-up time.
+func (o *Orchestrator) startTimer(ctx context.Context, state *domain.WorkflowState, step domain.StepDefinition) error {
+	wakeAt, ok := state.Timers[step.Name]
+	if !ok {
+		in := domain.TransitionInput{Outputs: state.StepOutputs}
+		if step.Timer.Until != "" {
+			data, err := o.orderData(ctx, state.RootOrderID)
+			if err != nil {
+				return err
+			}
+			in.Order = data
+		}
+		var err error
+		if wakeAt, err = step.Timer.WakeAt(time.Now(), in); err != nil {
+			return fmt.Errorf("failed to compute wake-up time of %s: %w", step.Name, err)
+		}
+	}
+	return o.scheduleTimer(ctx, state.OrderID, step.Name, wakeAt)
+}
+
+// scheduleTimer persists when the timer of step fires, unless it already has
+// a wake-up time, and schedules the task that fires it.
+func (o *Orchestrator) scheduleTimer(ctx context.Context, orderID string, step domain.Step, wakeAt time.Time) error {
+	return o.transaction(ctx, func(ctx context.Context) error {
+		wakeAt, err := o.workflows.SetTimer(ctx, orderID, step, wakeAt)
+		if err != nil {
+			return err
+		}
+		payload := queue.StepPayload{OrderID: orderID, Step: step}
+		if err := o.enqueue(ctx, "timer", payload, queue.TimerTaskID(orderID, step), wakeAt); err != nil {
+			return err
+		}
+		o.logger.Info("Timer scheduled",
+			zap.String("order_id", orderID),
+			zap.String("step", string(step)),
+			zap.Time("wake_at", wakeAt))
+		return nil
+	})
+}
+
+// FireTimer completes a timer step, or fails a signal step whose signal did
+// not arrive before its timeout. A timer that fires after the workflow moved
+// on is ignored.
+func (o *Orchestrator) FireTimer(ctx context.Context, orderID string, step domain.Step) error {
+	spanCtx, span := o.tracer.Start(ctx, "fire_timer")
+	defer span.End()
+
+	state, err := o.workflows.GetStateByOrderID(spanCtx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if state == nil {
+		return fmt.Errorf("workflow not found for order %s", orderID)
+	}
+	if !state.Status.IsActive() || !slices.Contains(state.ActiveSteps, step) {
+		o.logger.Info("Timer fired for inactive step, ignoring",
+			zap.String("order_id", orderID),
+			zap.String("step", string(step)))
+		return nil
+	}
+	def, err := o.definitions.For(state)
+	if err != nil {
+		return err
+	}
+
+	if stepDef, _ := def.Step(step); stepDef.IsSignal() {
+		o.logger.Warn("Signal timed out",
+			zap.String("order_id", orderID),
+			zap.String("step", string(step)),
+			zap.String("signal", stepDef.Signal.Name))
+		return o.Compensate(spanCtx, orderID, step, fmt.Errorf("signal %s timed out", stepDef.Signal.Name))
+	}
+
+	o.logger.Info("Timer fired",
+		zap.String("order_id", orderID),
+		zap.String("step", string(step)))
+	return o.NextStep(spanCtx, orderID, step, map[string]any{"fired_at": time.Now().UTC().Format(time.RFC3339)})
+}
+
+func (o *Orchestrator) orderData(ctx context.Context, orderID string) (map[string]any, error) {
+	order, err := o.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	return order.Data, nil
+}
@@ -0,0 +1,34 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+	"go.uber.org/zap"
+)
+
+const maxConflictRetries = 5
+
+// txKey marks a context that runs inside transaction.
+type txKey struct{}
+
+// transaction runs fn in a transaction, or in the one already running in ctx.
+// When fn loses a race with another worker changing the same workflow, the
+// outermost transaction is rolled back and fn runs again, so it re-reads the
+// state and decides again.
+func (o *Orchestrator) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return o.transactor.WithinTransaction(ctx, fn)
+	}
+	ctx = context.WithValue(ctx, txKey{}, true)
+	for attempt := 1; ; attempt++ {
+		err := o.transactor.WithinTransaction(ctx, fn)
+		if !errors.Is(err, domain.ErrVersionConflict) || attempt == maxConflictRetries {
+			return err
+		}
+		o.logger.Info("Workflow changed concurrently, retrying",
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+	}
+}
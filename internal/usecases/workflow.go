@@ -2,170 +2,310 @@ package usecases
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
-	"github.com/hibiken/asynq"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/queue"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/tracing"
 	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
 	"go.uber.org/zap"
 )
 
-var logger *zap.Logger
+// StartWorkflow creates the order with the given attributes and enqueues the
+// first step of the workflow definition.
+func (o *Orchestrator) StartWorkflow(ctx context.Context, orderID string, data map[string]any) error {
+	spanCtx, span := o.tracer.Start(ctx, "start_workflow")
+	defer span.End()
 
-func init() {
-	var err error
-	logger, err = zap.NewProduction()
-	if err != nil {
-		panic(fmt.Sprintf("failed to init logger: %v", err))
-	}
+	return o.startWorkflow(spanCtx, o.definitions.Default(), orderID, data, nil, "")
 }
 
-func StartWorkflow(ctx context.Context, orderID string) error {
-	cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
-
-	orderRepo := repositories.NewOrderRepo(db)
-	workflowRepo := repositories.NewWorkflowRepo(db)
-
-	order := &domain.Order{
-		ID:        orderID,
-		Status:    "pending",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-	if err := orderRepo.SaveOrder(ctx, order); err != nil {
-		return fmt.Errorf("failed to save order: %w", err)
-	}
-
-	state := &domain.WorkflowState{
-		OrderID:     orderID,
-		CurrentStep: domain.StepReserveSlot,
-		Status:      domain.StatusPending,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
-	if err := workflowRepo.SaveState(ctx, state); err != nil {
-		return fmt.Errorf("failed to save workflow state: %w", err)
+// startWorkflow creates the workflow state for def and enqueues its first
+// step. A top-level workflow creates its order with data; a child workflow,
+// started by parentStep of parent, runs for the order of parent instead.
+func (o *Orchestrator) startWorkflow(ctx context.Context, def *domain.WorkflowDefinition, orderID string, data map[string]any, parent *domain.WorkflowState, parentStep domain.Step) error {
+	firstStep := def.FirstStep()
+	runnable, err := def.Runnable(firstStep)
+	if err != nil {
+		return err
 	}
 
-	client := queue.NewQueueClient()
-	defer client.Close()
+	err = o.transaction(ctx, func(ctx context.Context) error {
+		existing, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if existing != nil {
+			return fmt.Errorf("%w for order %s", domain.ErrWorkflowExists, orderID)
+		}
 
-	spanCtx, span := tracing.Tracer.Start(ctx, "start_workflow")
-	defer span.End()
+		state := &domain.WorkflowState{
+			OrderID:         orderID,
+			RootOrderID:     orderID,
+			Workflow:        def.Name,
+			WorkflowVersion: def.Version,
+			ParentStep:      parentStep,
+			CurrentStep:     firstStep,
+			ActiveSteps:     runnable,
+			Status:          domain.StatusPending,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+		if parent != nil {
+			state.RootOrderID = parent.RootOrderID
+			state.ParentOrderID = parent.OrderID
+		} else {
+			order := &domain.Order{
+				ID:        orderID,
+				Status:    "pending",
+				Data:      data,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			if err := o.orders.SaveOrder(ctx, order); err != nil {
+				return fmt.Errorf("failed to save order: %w", err)
+			}
+		}
+		if err := o.workflows.SaveState(ctx, state); err != nil {
+			return fmt.Errorf("failed to save workflow state: %w", err)
+		}
+		if err := o.recordTransition(ctx, orderID, position{}, positionOf(state), "workflow started", nil); err != nil {
+			return err
+		}
 
-	payload, err := json.Marshal(queue.StepPayload{OrderID: orderID, Step: domain.StepReserveSlot})
+		if err := o.enqueueSteps(ctx, def, orderID, runnable); err != nil {
+			return fmt.Errorf("failed to enqueue first step: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-	task := asynq.NewTask("step", payload)
-	if _, err := client.EnqueueContext(spanCtx, task); err != nil {
-		return fmt.Errorf("failed to enqueue first step: %w", err)
+		return err
 	}
 
-	logger.Info("Started workflow",
+	fields := []zap.Field{
 		zap.String("order_id", orderID),
-		zap.String("step", string(domain.StepReserveSlot)))
+		zap.String("workflow", def.Name),
+		zap.Int("version", def.Version),
+		zap.String("step", string(firstStep)),
+	}
+	if parent != nil {
+		fields = append(fields, zap.String("parent_order_id", parent.OrderID))
+	}
+	o.logger.Info("Started workflow", fields...)
 	return nil
 }
 
-func NextStep(ctx context.Context, orderID string, currentStep domain.Step) error {
-	spanCtx, span := tracing.Tracer.Start(ctx, "next_step")
+// NextStep records that completedStep succeeded with output and, once nothing
+// else is active for the current step, moves the workflow to the next one.
+func (o *Orchestrator) NextStep(ctx context.Context, orderID string, completedStep domain.Step, output map[string]any) error {
+	spanCtx, span := o.tracer.Start(ctx, "next_step")
 	defer span.End()
 
-    cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
-
-    workflowRepo := repositories.NewWorkflowRepo(db)
-    state, err := workflowRepo.GetStateByOrderID(spanCtx, orderID)
-    if err != nil {
-        return fmt.Errorf("failed to get workflow state: %w", err)
-    }
-    if state == nil {
-        return fmt.Errorf("workflow not found for order %s", orderID)
-    }
-
-    var nextStep domain.Step
-    switch currentStep {
-    case domain.StepReserveSlot:
-        nextStep = domain.StepAssignAgent
-    case domain.StepAssignAgent:
-        nextStep = domain.StepNotifyCustomer
-    case domain.StepNotifyCustomer:
-        return MarkCompleted(spanCtx, orderID)
-    default:
-        return fmt.Errorf("unknown current step: %s", currentStep)
-    }
-
-    state.CurrentStep = nextStep
-    state.UpdatedAt = time.Now()
-    if err := workflowRepo.SaveState(spanCtx, state); err != nil {
-        return fmt.Errorf("failed to update workflow state: %w", err)
-    }
-
-    client := queue.NewQueueClient()
-    defer client.Close()
-
-    payload, err := json.Marshal(queue.StepPayload{OrderID: orderID, Step: nextStep})
-    if err != nil {
-        return fmt.Errorf("failed to marshal payload: %w", err)
-    }
-    task := asynq.NewTask("step", payload)
-    if _, err := client.EnqueueContext(spanCtx, task); err != nil {
-        return fmt.Errorf("failed to enqueue step %s: %w", nextStep, err)
-    }
-
-    logger.Info("Advanced workflow",
-        zap.String("order_id", orderID),
-        zap.String("next_step", string(nextStep)))
-    return nil
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		state, err := o.workflows.CompleteStep(ctx, orderID, completedStep, output)
+		if err != nil {
+			return fmt.Errorf("failed to record step completion: %w", err)
+		}
+		if state == nil {
+			o.logger.Info("Step is not active, ignoring",
+				zap.String("order_id", orderID),
+				zap.String("step", string(completedStep)))
+			return nil
+		}
+		def, err := o.definitions.For(state)
+		if err != nil {
+			return err
+		}
+		paused := state.Status == domain.StatusPaused
+		if !state.Status.IsActive() && !paused {
+			// the saga was rolled back while this branch was still running
+			return o.compensateLateStep(ctx, orderID, def, completedStep)
+		}
+		from := positionOf(state)
+		cause := fmt.Sprintf("step %s succeeded", completedStep)
+		if len(state.ActiveSteps) > 0 {
+			o.logger.Info("Waiting for parallel branches",
+				zap.String("order_id", orderID),
+				zap.String("step", string(state.CurrentStep)),
+				zap.Any("active_steps", state.ActiveSteps))
+			return o.recordTransition(ctx, orderID, from, from, cause, nil)
+		}
+
+		current, _ := def.Step(state.CurrentStep)
+		in := domain.TransitionInput{Outputs: state.StepOutputs}
+		if len(current.Transitions) > 0 {
+			if in.Order, err = o.orderData(ctx, state.RootOrderID); err != nil {
+				return err
+			}
+		}
+		nextStep, transition, err := def.NextStep(state.CurrentStep, in)
+		if err != nil {
+			return err
+		}
+		if len(current.Transitions) > 0 {
+			if transition == "" {
+				transition = "default"
+			}
+			cause += fmt.Sprintf(", took transition %s", transition)
+			state.Branches = append(state.Branches, domain.BranchDecision{
+				Step:       state.CurrentStep,
+				Transition: transition,
+				Goto:       nextStep,
+				DecidedAt:  time.Now(),
+			})
+			o.logger.Info("Took transition",
+				zap.String("order_id", orderID),
+				zap.String("step", string(state.CurrentStep)),
+				zap.String("transition", transition),
+				zap.String("goto", string(nextStep)))
+		}
+		if nextStep == domain.StepEnd {
+			if err := o.workflows.SaveState(ctx, state); err != nil {
+				return fmt.Errorf("failed to update workflow state: %w", err)
+			}
+			return o.MarkCompleted(ctx, orderID)
+		}
+		runnable, err := def.Runnable(nextStep)
+		if err != nil {
+			return err
+		}
+
+		state.CurrentStep = nextStep
+		state.ActiveSteps = runnable
+		if paused {
+			state.HeldSteps = append(state.HeldSteps, runnable...)
+		}
+		state.UpdatedAt = time.Now()
+		if err := o.workflows.SaveState(ctx, state); err != nil {
+			return fmt.Errorf("failed to update workflow state: %w", err)
+		}
+		if err := o.recordTransition(ctx, orderID, from, positionOf(state), cause, nil); err != nil {
+			return err
+		}
+
+		if paused {
+			// held, resuming the workflow enqueues them
+			o.logger.Info("Advanced paused workflow",
+				zap.String("order_id", orderID),
+				zap.String("next_step", string(nextStep)))
+			return nil
+		}
+		if err := o.enqueueSteps(ctx, def, orderID, runnable); err != nil {
+			return err
+		}
+
+		o.logger.Info("Advanced workflow",
+			zap.String("order_id", orderID),
+			zap.String("next_step", string(nextStep)))
+		return nil
+	})
+}
+
+// StepRunnable reports whether step is to run now in the workflow of orderID.
+// A step enqueued before its workflow was cancelled or rolled back is not, nor
+// is one of a paused workflow, which holds it. A step a pause holds pauses its
+// workflow instead. Resuming the workflow enqueues the steps it held again.
+func (o *Orchestrator) StepRunnable(ctx context.Context, orderID string, step domain.Step) (bool, error) {
+	var runnable bool
+	err := o.transaction(ctx, func(ctx context.Context) error {
+		state, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		if !slices.Contains(state.ActiveSteps, step) {
+			return nil
+		}
+		if state.Status == domain.StatusPaused {
+			return o.hold(ctx, state, step)
+		}
+		if !state.Status.IsActive() {
+			return nil
+		}
+		pause, err := o.pauseHolding(ctx, []domain.Step{step})
+		if err != nil {
+			return err
+		}
+		if pause == nil {
+			runnable = true
+			return nil
+		}
+		cause := "paused before step " + string(step)
+		if pause.Reason != "" {
+			cause += ": " + pause.Reason
+		}
+		return o.pause(ctx, state, pause.Origin(), []domain.Step{step}, cause)
+	})
+	return runnable, err
 }
 
-func MarkCompleted(ctx context.Context, orderID string) error {
-	spanCtx, span := tracing.Tracer.Start(ctx, "mark_completed")
-    defer span.End()
+func (o *Orchestrator) MarkCompleted(ctx context.Context, orderID string) error {
+	spanCtx, span := o.tracer.Start(ctx, "mark_completed")
+	defer span.End()
 
-	cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
+	return o.transaction(spanCtx, func(ctx context.Context) error {
+		workflow, err := o.workflows.GetStateByOrderID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get workflow state: %w", err)
+		}
+		if workflow == nil {
+			return fmt.Errorf("workflow not found for order %s", orderID)
+		}
+		if err := o.setOrderStatus(ctx, workflow, "fulfilled"); err != nil {
+			return err
+		}
+		from := positionOf(workflow)
+		workflow.Status = domain.StatusCompleted
+		workflow.PausedBy = ""
+		workflow.HeldSteps = nil
+		workflow.UpdatedAt = time.Now()
+		if err := o.workflows.SaveState(ctx, workflow); err != nil {
+			return fmt.Errorf("failed to update workflow state: %w", err)
+		}
+		if err := o.recordTransition(ctx, orderID, from, positionOf(workflow), "workflow completed", nil); err != nil {
+			return err
+		}
 
-	orderRepo := repositories.NewOrderRepo(db)
-	workflowRepo := repositories.NewWorkflowRepo(db)
+		o.logger.Info("Workflow completed", zap.String("order_id", orderID))
+		if workflow.ParentOrderID != "" {
+			return o.NextStep(ctx, workflow.ParentOrderID, workflow.ParentStep, childOutput(orderID))
+		}
+		return nil
+	})
+}
 
-	order, err := orderRepo.GetOrderByID(spanCtx, orderID)
+// setOrderStatus sets the status of the order of workflow. A child workflow
+// leaves its order to its top-level workflow and changes nothing.
+func (o *Orchestrator) setOrderStatus(ctx context.Context, workflow *domain.WorkflowState, status string) error {
+	if workflow.ParentOrderID != "" {
+		return nil
+	}
+	order, err := o.orders.GetOrderByID(ctx, workflow.OrderID)
 	if err != nil {
-		return fmt.Errorf("failed to get order: %w",err)
+		return fmt.Errorf("failed to get order: %w", err)
 	}
 	if order == nil {
-		return fmt.Errorf("order %s not found", orderID)
+		return fmt.Errorf("order %s not found", workflow.OrderID)
 	}
-	order.Status = "fulfilled"
-    order.UpdatedAt = time.Now()
-    if err := orderRepo.SaveOrder(spanCtx, order); err != nil {
-        return fmt.Errorf("failed to update order status: %w", err)
-    }
-
-    workflow, err := workflowRepo.GetStateByOrderID(spanCtx, orderID)
-    if err != nil {
-        return fmt.Errorf("failed to get workflow state: %w", err)
-    }
-    if workflow == nil {
-        return fmt.Errorf("workflow not found for order %s", orderID)
-    }
-    workflow.Status = domain.StatusCompleted
-    workflow.UpdatedAt = time.Now()
-    if err := workflowRepo.SaveState(spanCtx, workflow); err != nil {
-        return fmt.Errorf("failed to update workflow state: %w", err)
-    }
-
-    logger.Info("Workflow completed", zap.String("order_id", orderID))
-    return nil
-}
\ No newline at end of file
+	order.Status = status
+	order.UpdatedAt = time.Now()
+	if err := o.orders.SaveOrder(ctx, order); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	return nil
+}
+
+// enqueueSteps enqueues a "step" task for each of steps with the retry policy
+// def declares for it, bounded by domain.DefaultRetryPolicy.
+func (o *Orchestrator) enqueueSteps(ctx context.Context, def *domain.WorkflowDefinition, orderID string, steps []domain.Step) error {
+	for _, step := range steps {
+		stepDef, _ := def.Step(step)
+		payload := queue.StepPayload{OrderID: orderID, Step: step, Retry: stepDef.Retry.WithDefaults()}
+		if err := o.enqueue(ctx, "step", payload, "", time.Time{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+ALTER TABLE workflows
+    DROP COLUMN active_steps,
+    DROP COLUMN completed_steps;
\ No newline at end of file
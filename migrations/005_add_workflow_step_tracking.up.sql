@@ -0,0 +1,5 @@
+ALTER TABLE workflows
+    ADD COLUMN active_steps TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN completed_steps TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE workflows SET active_steps = ARRAY[current_step] WHERE status = 'pending';
@@ -0,0 +1,7 @@
+ALTER TABLE step_executions DROP COLUMN output;
+
+ALTER TABLE workflows
+    DROP COLUMN step_outputs,
+    DROP COLUMN branches;
+
+ALTER TABLE orders DROP COLUMN data;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE orders ADD COLUMN data JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE workflows
+    ADD COLUMN step_outputs JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN branches JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE step_executions ADD COLUMN output JSONB;
@@ -0,0 +1,6 @@
+DROP INDEX idx_workflows_parent_order_id;
+
+ALTER TABLE workflows
+    DROP COLUMN workflow_name,
+    DROP COLUMN parent_order_id,
+    DROP COLUMN parent_step;
\ No newline at end of file
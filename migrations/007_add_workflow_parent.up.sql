@@ -0,0 +1,6 @@
+ALTER TABLE workflows
+    ADD COLUMN workflow_name VARCHAR(100) NOT NULL DEFAULT 'order_fulfillment',
+    ADD COLUMN parent_order_id VARCHAR(255) REFERENCES workflows(order_id),
+    ADD COLUMN parent_step VARCHAR(50);
+
+CREATE INDEX idx_workflows_parent_order_id ON workflows(parent_order_id);
@@ -0,0 +1,3 @@
+DROP INDEX idx_workflows_definition;
+
+ALTER TABLE workflows DROP COLUMN workflow_version;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE workflows ADD COLUMN workflow_version INT NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_workflows_definition ON workflows(workflow_name, workflow_version, status);
@@ -0,0 +1 @@
+ALTER TABLE workflows DROP COLUMN timers;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE workflows ADD COLUMN timers JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+DROP TABLE compensation_executions;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE compensation_executions (
+    id SERIAL PRIMARY KEY,
+    dedupe_key VARCHAR(255) NOT NULL UNIQUE,
+    order_id VARCHAR(255) NOT NULL REFERENCES orders(id),
+    compensation VARCHAR(50) NOT NULL,
+    result VARCHAR(50) NOT NULL,
+    attempts INT NOT NULL DEFAULT 1,
+    last_error TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_compensation_executions_order_id ON compensation_executions(order_id);
@@ -0,0 +1 @@
+ALTER TABLE workflows DROP COLUMN pending_compensations;
\ No newline at end of file
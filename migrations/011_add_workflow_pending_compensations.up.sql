@@ -0,0 +1 @@
+ALTER TABLE workflows ADD COLUMN pending_compensations TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+ALTER TABLE workflows DROP COLUMN compensation_failure;
\ No newline at end of file
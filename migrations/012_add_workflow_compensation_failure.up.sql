@@ -0,0 +1 @@
+ALTER TABLE workflows ADD COLUMN compensation_failure JSONB;
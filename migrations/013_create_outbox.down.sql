@@ -0,0 +1 @@
+DROP TABLE outbox;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    task_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    task_id VARCHAR(255),
+    process_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_unsent ON outbox(id) WHERE sent_at IS NULL;
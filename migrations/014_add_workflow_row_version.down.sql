@@ -0,0 +1 @@
+ALTER TABLE workflows DROP COLUMN version;
\ No newline at end of file
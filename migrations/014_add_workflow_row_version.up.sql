@@ -0,0 +1 @@
+ALTER TABLE workflows ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
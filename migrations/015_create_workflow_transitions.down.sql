@@ -0,0 +1 @@
+DROP TABLE workflow_transitions;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE workflow_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    order_id VARCHAR(255) NOT NULL REFERENCES orders(id),
+    from_step VARCHAR(50),
+    to_step VARCHAR(50),
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    cause VARCHAR(255) NOT NULL,
+    error TEXT,
+    worker VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_workflow_transitions_order_id ON workflow_transitions(order_id, id);
@@ -0,0 +1,2 @@
+DROP TABLE workflow_snapshots;
+DROP TABLE workflow_events;
\ No newline at end of file
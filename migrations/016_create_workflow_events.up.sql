@@ -0,0 +1,15 @@
+CREATE TABLE workflow_events (
+    order_id VARCHAR(255) NOT NULL,
+    sequence BIGINT NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (order_id, sequence)
+);
+
+CREATE TABLE workflow_snapshots (
+    order_id VARCHAR(255) PRIMARY KEY,
+    sequence BIGINT NOT NULL,
+    state JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+DROP INDEX idx_workflows_created_at;
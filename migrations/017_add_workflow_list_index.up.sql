@@ -0,0 +1 @@
+CREATE INDEX idx_workflows_created_at ON workflows(created_at DESC, order_id DESC);
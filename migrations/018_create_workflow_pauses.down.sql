@@ -0,0 +1 @@
+DROP TABLE workflow_pauses;
\ No newline at end of file
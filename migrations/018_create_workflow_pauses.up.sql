@@ -0,0 +1,5 @@
+CREATE TABLE workflow_pauses (
+    step VARCHAR(50) PRIMARY KEY, -- empty for a pause of every step
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
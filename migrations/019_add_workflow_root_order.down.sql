@@ -0,0 +1,9 @@
+ALTER TABLE compensation_executions
+    DROP CONSTRAINT compensation_executions_order_id_fkey,
+    ADD CONSTRAINT compensation_executions_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id);
+ALTER TABLE workflow_transitions
+    DROP CONSTRAINT workflow_transitions_order_id_fkey,
+    ADD CONSTRAINT workflow_transitions_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id);
+ALTER TABLE workflows ADD CONSTRAINT workflows_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id);
+
+ALTER TABLE workflows DROP COLUMN root_order_id;
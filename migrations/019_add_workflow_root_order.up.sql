@@ -0,0 +1,13 @@
+-- child workflows are keyed by <parent>/<step> and reach their order through
+-- root_order_id, so their keys no longer need a row in orders
+ALTER TABLE workflows ADD COLUMN root_order_id VARCHAR(255) REFERENCES orders(id);
+UPDATE workflows SET root_order_id = split_part(order_id, '/', 1);
+ALTER TABLE workflows ALTER COLUMN root_order_id SET NOT NULL;
+
+ALTER TABLE workflows DROP CONSTRAINT workflows_order_id_fkey;
+ALTER TABLE workflow_transitions
+    DROP CONSTRAINT workflow_transitions_order_id_fkey,
+    ADD CONSTRAINT workflow_transitions_order_id_fkey FOREIGN KEY (order_id) REFERENCES workflows(order_id);
+ALTER TABLE compensation_executions
+    DROP CONSTRAINT compensation_executions_order_id_fkey,
+    ADD CONSTRAINT compensation_executions_order_id_fkey FOREIGN KEY (order_id) REFERENCES workflows(order_id);
@@ -0,0 +1 @@
+ALTER TABLE workflows DROP COLUMN held_steps, DROP COLUMN paused_by;
@@ -0,0 +1,16 @@
+ALTER TABLE workflows
+    ADD COLUMN paused_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN held_steps TEXT[] NOT NULL DEFAULT '{}';
+
+-- workflows paused before: a pause of a step left its step in the cause of
+-- the transition, and resuming enqueued every active step
+UPDATE workflows w SET
+    paused_by = CASE WHEN t.cause LIKE 'paused before step %' THEN 'step' ELSE 'workflow' END,
+    held_steps = w.active_steps
+FROM (
+    SELECT DISTINCT ON (order_id) order_id, cause
+    FROM workflow_transitions
+    WHERE to_status = 'paused'
+    ORDER BY order_id, id DESC
+) t
+WHERE w.order_id = t.order_id AND w.status = 'paused';
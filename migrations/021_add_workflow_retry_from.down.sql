@@ -0,0 +1 @@
+ALTER TABLE workflows DROP COLUMN retry_from;
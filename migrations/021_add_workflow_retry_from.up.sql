@@ -0,0 +1 @@
+ALTER TABLE workflows ADD COLUMN retry_from VARCHAR(50) NOT NULL DEFAULT '';
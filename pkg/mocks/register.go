@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/adapters/executors"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
+)
+
+// Register wires the mock services as the executors and compensators of the
+// default order fulfillment steps. Agents are assigned in agents.
+func Register(reg *executors.Registry, agents domain.AgentRepo) {
+	reg.RegisterStep(domain.StepReserveSlot, domain.StepExecutorFunc(func(_ context.Context, orderID string) (map[string]any, error) {
+		slotID, err := ReserveSlot(orderID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"slot_id": slotID}, nil
+	}))
+	reg.RegisterStep(domain.StepAssignAgent, domain.StepExecutorFunc(func(ctx context.Context, orderID string) (map[string]any, error) {
+		agentIDs, err := AssignAgent(ctx, agents, orderID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"agent_ids": agentIDs}, nil
+	}))
+	reg.RegisterStep(domain.StepReserveStation, domain.StepExecutorFunc(func(_ context.Context, orderID string) (map[string]any, error) {
+		stationID, err := ReservePackagingStation(orderID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"station_id": stationID}, nil
+	}))
+	reg.RegisterStep(domain.StepNotifyCustomer, domain.StepExecutorFunc(func(_ context.Context, orderID string) (map[string]any, error) {
+		return nil, NotifyCustomer(orderID)
+	}))
+
+	reg.RegisterCompensation(domain.CompReleaseSlot, domain.CompensatorFunc(func(_ context.Context, orderID string) error {
+		return ReleaseSlot(orderID)
+	}))
+	reg.RegisterCompensation(domain.CompUnassignAgent, domain.CompensatorFunc(func(ctx context.Context, orderID string) error {
+		return UnassignAgent(ctx, agents, orderID)
+	}))
+	reg.RegisterCompensation(domain.CompReleaseStation, domain.CompensatorFunc(func(_ context.Context, orderID string) error {
+		return ReleasePackagingStation(orderID)
+	}))
+	reg.RegisterCompensation(domain.CompCancelNotification, domain.CompensatorFunc(func(_ context.Context, orderID string) error {
+		return CancelNotification(orderID)
+	}))
+}
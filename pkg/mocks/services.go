@@ -6,39 +6,30 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/config"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/repositories"
-	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/pkg/conn"
+	"github.com/mahabubulhasibshawon/Task_Saga_Workflow_Orchestrator.git/internal/domain"
 )
 
 var (
 	slots = sync.Map{}
 	notifications = sync.Map{}
+	stations = sync.Map{}
 )
 
+// ReserveSlot reserves a slot for the order, or returns the one it already
+// holds, so that a retried step does not fail on its own reservation.
 func ReserveSlot(orderID string) (string, error) {
-	if _, exists := slots.Load(orderID); exists {
-		return "", fmt.Errorf("slots already reserver for order %s", orderID)
-	}
-	slotID := "slot-"+orderID
-	slots.Store(orderID, slotID)
-	return slotID, nil
+	slotID, _ := slots.LoadOrStore(orderID, "slot-"+orderID)
+	return slotID.(string), nil
 }
 
+// ReleaseSlot releases the slot of the order. A slot already released is not
+// an error, so the compensation can run again.
 func ReleaseSlot(orderID string) (error) {
-	if _, exists := slots.Load(orderID); !exists {
-		return fmt.Errorf("no slot exist for order %s", orderID)
-	}
 	slots.Delete(orderID)
 	return nil
 }
 
-func AssignAgent(ctx context.Context, orderID string) ([]string, error) {
-	cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
-
-	repo := repositories.NewAgentRepo(db)
+func AssignAgent(ctx context.Context, repo domain.AgentRepo, orderID string) ([]string, error) {
 	agentIDs := []string{uuid.NewString(), uuid.NewString()} // Assign two agents
     for _, agentID := range agentIDs {
         if err := repo.AssignAgent(ctx, orderID, agentID); err != nil {
@@ -48,28 +39,34 @@ func AssignAgent(ctx context.Context, orderID string) ([]string, error) {
 	return agentIDs, nil
 }
 
-func UnassignAgent(ctx context.Context, orderID string) error {
-    cfg := config.Load()
-	db := conn.ConnectPostgres(cfg.DSN())
-	defer db.Close()
-
-    repo := repositories.NewAgentRepo(db)
+func UnassignAgent(ctx context.Context, repo domain.AgentRepo, orderID string) error {
     return repo.UnassignAgents(ctx, orderID)
 }
 
+// NotifyCustomer notifies the customer of the order once; notifying again is
+// not an error.
 func NotifyCustomer(orderID string) error {
-    if _, exists := notifications.Load(orderID); exists {
-        return fmt.Errorf("notification already sent for order %s", orderID)
-    }
-    message := "Order " + orderID + " is ready for pickup"
-    notifications.Store(orderID, message)
+    notifications.LoadOrStore(orderID, "Order " + orderID + " is ready for pickup")
     return nil
 }
 
+// CancelNotification cancels the notification of the order, if it was not
+// cancelled already.
 func CancelNotification(orderID string) error {
-    if _, exists := notifications.Load(orderID); !exists {
-        return fmt.Errorf("no notification found for order %s", orderID)
-    }
     notifications.Delete(orderID)
     return nil
-}
\ No newline at end of file
+}
+
+// ReservePackagingStation reserves a station for the order, or returns the one
+// it already holds.
+func ReservePackagingStation(orderID string) (string, error) {
+	stationID, _ := stations.LoadOrStore(orderID, "station-"+orderID)
+	return stationID.(string), nil
+}
+
+// ReleasePackagingStation releases the station of the order, if it was not
+// released already.
+func ReleasePackagingStation(orderID string) error {
+	stations.Delete(orderID)
+	return nil
+}
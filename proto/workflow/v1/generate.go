@@ -0,0 +1,5 @@
+// Package workflowv1 holds the code generated from workflow.proto. Change the
+// proto and run go generate; never edit the .pb.go files by hand.
+package workflowv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative proto/workflow/v1/workflow.proto